@@ -0,0 +1,508 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"goDemo/dialect"
+	"net"
+	"strings"
+	"time"
+)
+
+// Config 应用配置
+// 加载优先级（由低到高）：默认值 < 配置文件 < 环境变量 < 命令行参数
+// 每个叶子字段的键名由 yaml 标签拼接而成，例如 server.addr，
+// 对应环境变量 REALWORLD_SERVER_ADDR 与命令行参数 -server.addr
+type Config struct {
+	Server       ServerConfig       `yaml:"server" toml:"server"`
+	Database     DatabaseConfig     `yaml:"database" toml:"database"`
+	Auth         AuthConfig         `yaml:"auth" toml:"auth"`
+	CORS         CORSConfig         `yaml:"cors" toml:"cors"`
+	Mail         MailConfig         `yaml:"mail" toml:"mail"`
+	Account      AccountConfig      `yaml:"account" toml:"account"`
+	Registration RegistrationConfig `yaml:"registration" toml:"registration"`
+	Publishing   PublishingConfig   `yaml:"publishing" toml:"publishing"`
+}
+
+// PublishingConfig 定时发布与发布通知，通知在文章首次发布时发送，定时发布的文章在到期发布时才发送，取消发布后再次发布不会重复通知
+type PublishingConfig struct {
+	SchedulerInterval Duration      `yaml:"scheduler_interval" toml:"scheduler_interval" usage:"后台检查到期定时发布文章的间隔，0 表示不在服务内发布，改用 publish-scheduled 子命令"`
+	NotifyFollowers   bool          `yaml:"notify_followers" toml:"notify_followers" usage:"文章发布时给作者的关注者发送邮件通知"`
+	Webhooks          WebhookConfig `yaml:"webhooks" toml:"webhooks"`
+}
+
+// WebhookConfig 文章发布时向外部地址推送 article.published 事件
+type WebhookConfig struct {
+	URLs        []string `yaml:"urls" toml:"urls" usage:"接收推送的地址，逗号分隔，为空表示不推送"`
+	Secret      Secret   `yaml:"secret" toml:"secret" usage:"推送内容的 HMAC-SHA256 签名密钥，为空时不签名"`
+	SecretFile  string   `yaml:"secret_file" toml:"secret_file" usage:"从文件读取推送签名密钥"`
+	Timeout     Duration `yaml:"timeout" toml:"timeout" usage:"单次推送的超时时间"`
+	MaxAttempts int      `yaml:"max_attempts" toml:"max_attempts" usage:"推送失败时的最大尝试次数"`
+}
+
+// RegistrationConfig 注册方式，invite_only 时注册必须填写邀请码，closed 时不开放注册（包括第三方登录创建账号）
+type RegistrationConfig struct {
+	Mode                  string   `yaml:"mode" toml:"mode" usage:"注册方式：open、invite_only、closed"`
+	InvitationTTL         Duration `yaml:"invitation_ttl" toml:"invitation_ttl" usage:"邀请码默认有效期，也是普通用户可设置的最长有效期，0 表示永不过期"`
+	UserInvitations       int      `yaml:"user_invitations" toml:"user_invitations" usage:"普通用户同时有效的邀请码数量上限，0 表示只有管理员可以创建"`
+	UserInvitationMaxUses int      `yaml:"user_invitation_max_uses" toml:"user_invitation_max_uses" usage:"普通用户创建的邀请码最多可使用的次数"`
+}
+
+// AccountConfig 账号注销
+type AccountConfig struct {
+	DeletionGracePeriod Duration `yaml:"deletion_grace_period" toml:"deletion_grace_period" usage:"申请注销后保留账号的时长，期间可以撤销"`
+	PurgeInterval       Duration `yaml:"purge_interval" toml:"purge_interval" usage:"后台清理到期账号的间隔，0 表示不在服务内清理，改用 purge-accounts 子命令"`
+	DeletedContent      string   `yaml:"deleted_content" toml:"deleted_content" usage:"注销账号发布的文章和评论：anonymize（保留并改为匿名作者）、delete（一并删除）"`
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	Addr      string `yaml:"addr" toml:"addr" usage:"HTTP 监听地址"`
+	PublicURL string `yaml:"public_url" toml:"public_url" usage:"前端访问地址，用于拼接邮件中的链接"`
+	// TrustedProxies 为空时不信任任何代理，客户端 IP 取连接的对端地址，忽略 X-Forwarded-For
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" usage:"可信的反向代理 IP 或 CIDR，逗号分隔；只有来自这些地址的 X-Forwarded-For 才会被采信"`
+}
+
+// DatabaseConfig 数据库配置
+type DatabaseConfig struct {
+	Driver      string `yaml:"driver" toml:"driver" usage:"数据库类型：mysql、postgres、sqlite"`
+	DSN         Secret `yaml:"dsn" toml:"dsn" usage:"数据库连接串"`
+	DSNFile     string `yaml:"dsn_file" toml:"dsn_file" usage:"从文件读取数据库连接串（如挂载的 secret）"`
+	AutoMigrate bool   `yaml:"auto_migrate" toml:"auto_migrate" usage:"启动时自动执行未执行的迁移"`
+}
+
+// AuthConfig JWT 认证配置
+type AuthConfig struct {
+	Secret     Secret `yaml:"secret" toml:"secret" usage:"JWT 签名密钥"`
+	SecretFile string `yaml:"secret_file" toml:"secret_file" usage:"从文件读取 JWT 签名密钥"`
+	// SigningKeys 额外的签名密钥，用于非对称签名和密钥轮换，仅支持在配置文件中设置
+	SigningKeys      []SigningKeyConfig `yaml:"signing_keys" toml:"signing_keys"`
+	KeyGracePeriod   Duration           `yaml:"key_grace_period" toml:"key_grace_period" usage:"旧签名密钥被替换后仍可用于验证的时长"`
+	AccessTokenTTL   Duration           `yaml:"access_token_ttl" toml:"access_token_ttl" usage:"访问令牌有效期，例如 15m"`
+	RefreshTokenTTL  Duration           `yaml:"refresh_token_ttl" toml:"refresh_token_ttl" usage:"刷新令牌有效期，例如 720h"`
+	PasswordResetTTL Duration           `yaml:"password_reset_ttl" toml:"password_reset_ttl" usage:"密码重置链接有效期"`
+	// 邮箱验证
+	EmailVerificationTTL       Duration `yaml:"email_verification_ttl" toml:"email_verification_ttl" usage:"邮箱验证链接有效期"`
+	VerificationResendInterval Duration `yaml:"verification_resend_interval" toml:"verification_resend_interval" usage:"重发验证邮件的最短间隔"`
+	UnverifiedRestrictions     []string `yaml:"unverified_restrictions" toml:"unverified_restrictions" usage:"未验证邮箱的用户不能执行的操作，逗号分隔：create_article、comment、favorite、follow"`
+	// 两步验证
+	TwoFactorIssuer       string                `yaml:"two_factor_issuer" toml:"two_factor_issuer" usage:"认证器应用中显示的服务名称"`
+	TwoFactorChallengeTTL Duration              `yaml:"two_factor_challenge_ttl" toml:"two_factor_challenge_ttl" usage:"密码验证通过后输入两步验证码的时限"`
+	ImpersonationTTL      Duration              `yaml:"impersonation_ttl" toml:"impersonation_ttl" usage:"管理员代入用户身份的令牌有效期"`
+	Lockout               LockoutConfig         `yaml:"lockout" toml:"lockout"`
+	PasswordHashing       PasswordHashingConfig `yaml:"password_hashing" toml:"password_hashing"`
+	PasswordPolicy        PasswordPolicyConfig  `yaml:"password_policy" toml:"password_policy"`
+	APIKeys               APIKeyConfig          `yaml:"api_keys" toml:"api_keys"`
+	OIDC                  OIDCConfig            `yaml:"oidc" toml:"oidc"`
+}
+
+// OIDCConfig 通过 OpenID Connect 身份提供方登录
+type OIDCConfig struct {
+	StateTTL Duration `yaml:"state_ttl" toml:"state_ttl" usage:"跳转到身份提供方后完成登录的时限"`
+	// Providers 身份提供方，仅支持在配置文件中设置
+	Providers []OIDCProviderConfig `yaml:"providers" toml:"providers"`
+}
+
+// OIDCProviderConfig 单个身份提供方，端点通过 issuer 的 /.well-known/openid-configuration 自动发现
+type OIDCProviderConfig struct {
+	Name             string   `yaml:"name" toml:"name"`                 // 路由中使用的标识，例如 google
+	DisplayName      string   `yaml:"display_name" toml:"display_name"` // 登录按钮上显示的名称，为空时使用 name
+	Issuer           string   `yaml:"issuer" toml:"issuer"`
+	ClientID         string   `yaml:"client_id" toml:"client_id"`
+	ClientSecret     Secret   `yaml:"client_secret" toml:"client_secret"` // 公开客户端只使用 PKCE 时可以为空
+	ClientSecretFile string   `yaml:"client_secret_file" toml:"client_secret_file"`
+	Scopes           []string `yaml:"scopes" toml:"scopes"`             // 为空时使用 openid email profile
+	RedirectURL      string   `yaml:"redirect_url" toml:"redirect_url"` // 为空时使用 <server.public_url>/oidc/<name>/callback
+	// LinkVerifiedEmail 提供方声明邮箱已验证时，首次登录自动关联同邮箱的已有账号
+	// 只应对可信的提供方开启，否则可能被用来接管账号
+	LinkVerifiedEmail bool `yaml:"link_verified_email" toml:"link_verified_email"`
+}
+
+// APIKeyConfig 个人 API 密钥
+type APIKeyConfig struct {
+	MaxPerUser int      `yaml:"max_per_user" toml:"max_per_user" usage:"每个用户最多持有的有效 API 密钥数"`
+	DefaultTTL Duration `yaml:"default_ttl" toml:"default_ttl" usage:"创建时未指定过期时间的 API 密钥有效期，0 表示永不过期"`
+	MaxTTL     Duration `yaml:"max_ttl" toml:"max_ttl" usage:"API 密钥有效期上限，0 表示不限制"`
+}
+
+// PasswordPolicyConfig 设置新密码时的校验规则，已有密码不受影响
+type PasswordPolicyConfig struct {
+	MinLength        int  `yaml:"min_length" toml:"min_length" usage:"密码最少字符数"`
+	MaxLength        int  `yaml:"max_length" toml:"max_length" usage:"密码最多字符数"`
+	RequireUppercase bool `yaml:"require_uppercase" toml:"require_uppercase" usage:"是否必须包含大写字母"`
+	RequireLowercase bool `yaml:"require_lowercase" toml:"require_lowercase" usage:"是否必须包含小写字母"`
+	RequireDigit     bool `yaml:"require_digit" toml:"require_digit" usage:"是否必须包含数字"`
+	RequireSymbol    bool `yaml:"require_symbol" toml:"require_symbol" usage:"是否必须包含符号"`
+	DisallowUserInfo bool `yaml:"disallow_user_info" toml:"disallow_user_info" usage:"密码中不能包含用户名或邮箱"`
+	// BreachedPasswordsPath 泄露密码库，可以是按 SHA-1 前缀分文件的目录，也可以是 SHA-1 列表文件
+	BreachedPasswordsPath string `yaml:"breached_passwords_path" toml:"breached_passwords_path" usage:"本地泄露密码库路径，为空时不检查"`
+}
+
+// PasswordHashingConfig 密码哈希配置，调整后用户下次登录时会自动按新配置重新哈希
+type PasswordHashingConfig struct {
+	Algorithm         string `yaml:"algorithm" toml:"algorithm" usage:"新密码使用的哈希算法：argon2id、bcrypt"`
+	Argon2Memory      int    `yaml:"argon2_memory" toml:"argon2_memory" usage:"argon2id 内存开销，单位 KiB"`
+	Argon2Iterations  int    `yaml:"argon2_iterations" toml:"argon2_iterations" usage:"argon2id 迭代次数"`
+	Argon2Parallelism int    `yaml:"argon2_parallelism" toml:"argon2_parallelism" usage:"argon2id 并行度"`
+	BcryptCost        int    `yaml:"bcrypt_cost" toml:"bcrypt_cost" usage:"bcrypt 计算成本"`
+}
+
+// LockoutConfig 登录失败限制
+type LockoutConfig struct {
+	Store            string   `yaml:"store" toml:"store" usage:"失败记录存储：database（多实例共享）、memory"`
+	MaxAttempts      int      `yaml:"max_attempts" toml:"max_attempts" usage:"单个账号连续失败多少次后锁定"`
+	MaxAttemptsPerIP int      `yaml:"max_attempts_per_ip" toml:"max_attempts_per_ip" usage:"单个 IP 连续失败多少次后锁定"`
+	BaseDelay        Duration `yaml:"base_delay" toml:"base_delay" usage:"第一次失败后的等待时间，之后每次翻倍"`
+	MaxDelay         Duration `yaml:"max_delay" toml:"max_delay" usage:"锁定前单次等待时间的上限"`
+	Duration         Duration `yaml:"duration" toml:"duration" usage:"锁定时长，距上次失败超过该时长后重新计数"`
+}
+
+// SigningKeyConfig 单把签名密钥
+type SigningKeyConfig struct {
+	ID             string    `yaml:"id" toml:"id"`
+	Algorithm      string    `yaml:"algorithm" toml:"algorithm"` // HS256、RS256、ES256、EdDSA
+	Secret         Secret    `yaml:"secret" toml:"secret"`       // HS256 使用
+	SecretFile     string    `yaml:"secret_file" toml:"secret_file"`
+	PrivateKeyFile string    `yaml:"private_key_file" toml:"private_key_file"` // 非对称算法使用，PEM 格式
+	ActiveFrom     time.Time `yaml:"active_from" toml:"active_from"`           // 开始用于签名的时间，为空表示立即生效
+}
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowOrigins     []string `yaml:"allow_origins" toml:"allow_origins" usage:"允许的来源，逗号分隔，* 表示全部"`
+	AllowMethods     []string `yaml:"allow_methods" toml:"allow_methods" usage:"允许的请求方法，逗号分隔"`
+	AllowHeaders     []string `yaml:"allow_headers" toml:"allow_headers" usage:"允许的请求头，逗号分隔"`
+	AllowCredentials bool     `yaml:"allow_credentials" toml:"allow_credentials" usage:"是否允许携带凭证"`
+}
+
+// MailConfig 邮件发送配置
+type MailConfig struct {
+	Driver string `yaml:"driver" toml:"driver" usage:"邮件发送方式：smtp、file、memory"`
+	From   string `yaml:"from" toml:"from" usage:"发件人地址"`
+	// Dir file 方式下邮件写入的目录，便于本地开发时查看
+	Dir  string     `yaml:"dir" toml:"dir" usage:"file 方式下邮件保存目录"`
+	SMTP SMTPConfig `yaml:"smtp" toml:"smtp"`
+}
+
+// SMTPConfig SMTP 服务器配置
+type SMTPConfig struct {
+	Host         string `yaml:"host" toml:"host" usage:"SMTP 服务器地址"`
+	Port         int    `yaml:"port" toml:"port" usage:"SMTP 端口"`
+	Username     string `yaml:"username" toml:"username" usage:"SMTP 用户名"`
+	Password     Secret `yaml:"password" toml:"password" usage:"SMTP 密码"`
+	PasswordFile string `yaml:"password_file" toml:"password_file" usage:"从文件读取 SMTP 密码"`
+}
+
+// Default 返回默认配置，密钥类字段没有默认值，必须显式提供
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:      ":8080",
+			PublicURL: "http://localhost:8080",
+		},
+		Database: DatabaseConfig{
+			Driver: "mysql",
+		},
+		Auth: AuthConfig{
+			KeyGracePeriod:             Duration(24 * time.Hour),
+			AccessTokenTTL:             Duration(15 * time.Minute),
+			RefreshTokenTTL:            Duration(30 * 24 * time.Hour),
+			PasswordResetTTL:           Duration(time.Hour),
+			EmailVerificationTTL:       Duration(48 * time.Hour),
+			VerificationResendInterval: Duration(time.Minute),
+			UnverifiedRestrictions:     []string{"create_article"},
+			TwoFactorIssuer:            "RealWorld",
+			TwoFactorChallengeTTL:      Duration(5 * time.Minute),
+			ImpersonationTTL:           Duration(30 * time.Minute),
+			Lockout: LockoutConfig{
+				Store:            "database",
+				MaxAttempts:      5,
+				MaxAttemptsPerIP: 50,
+				BaseDelay:        Duration(time.Second),
+				MaxDelay:         Duration(time.Minute),
+				Duration:         Duration(15 * time.Minute),
+			},
+			// argon2id 参数取自 OWASP 推荐值
+			PasswordHashing: PasswordHashingConfig{
+				Algorithm:         "argon2id",
+				Argon2Memory:      64 * 1024,
+				Argon2Iterations:  3,
+				Argon2Parallelism: 2,
+				BcryptCost:        12,
+			},
+			PasswordPolicy: PasswordPolicyConfig{
+				MinLength:        8,
+				MaxLength:        128,
+				DisallowUserInfo: true,
+			},
+			APIKeys: APIKeyConfig{
+				MaxPerUser: 20,
+				DefaultTTL: Duration(90 * 24 * time.Hour),
+				MaxTTL:     Duration(365 * 24 * time.Hour),
+			},
+			OIDC: OIDCConfig{
+				StateTTL: Duration(10 * time.Minute),
+			},
+		},
+		CORS: CORSConfig{
+			AllowOrigins:     []string{"*"},
+			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+			AllowCredentials: true,
+		},
+		Account: AccountConfig{
+			DeletionGracePeriod: Duration(30 * 24 * time.Hour),
+			PurgeInterval:       Duration(time.Hour),
+			DeletedContent:      "anonymize",
+		},
+		Registration: RegistrationConfig{
+			Mode:                  "open",
+			InvitationTTL:         Duration(7 * 24 * time.Hour),
+			UserInvitations:       5,
+			UserInvitationMaxUses: 1,
+		},
+		Publishing: PublishingConfig{
+			SchedulerInterval: Duration(30 * time.Second),
+			Webhooks: WebhookConfig{
+				Timeout:     Duration(5 * time.Second),
+				MaxAttempts: 3,
+			},
+		},
+		Mail: MailConfig{
+			Driver: "file",
+			From:   "noreply@realworld.local",
+			Dir:    "mailbox",
+			SMTP: SMTPConfig{
+				Port: 587,
+			},
+		},
+	}
+}
+
+// Validate 校验必填项，一次性返回所有错误
+func (c *Config) Validate() error {
+	var errs []error
+	if strings.TrimSpace(c.Server.Addr) == "" {
+		errs = append(errs, errors.New("server.addr 不能为空"))
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if !validProxy(proxy) {
+			errs = append(errs, fmt.Errorf("server.trusted_proxies 中的 %q 不是有效的 IP 或 CIDR", proxy))
+		}
+	}
+	if _, err := dialect.Lookup(c.Database.Driver); err != nil {
+		errs = append(errs, fmt.Errorf("database.driver 无效：%w", err))
+	}
+	if c.Database.DSN == "" {
+		errs = append(errs, errors.New("database.dsn 不能为空，可通过 database.dsn_file 从文件读取"))
+	}
+	if c.Auth.Secret == "" && len(c.Auth.SigningKeys) == 0 {
+		errs = append(errs, errors.New("auth.secret 与 auth.signing_keys 至少需要配置一项，可通过 auth.secret_file 从文件读取"))
+	} else if c.Auth.Secret != "" && len(c.Auth.Secret) < 32 {
+		errs = append(errs, errors.New("auth.secret 长度不能少于 32 个字符"))
+	}
+	seenKeys := make(map[string]bool)
+	for i, k := range c.Auth.SigningKeys {
+		prefix := fmt.Sprintf("auth.signing_keys[%d]", i)
+		if k.ID == "" || k.ID == "default" || seenKeys[k.ID] {
+			errs = append(errs, fmt.Errorf("%s.id 不能为空、不能为 default 且不能重复", prefix))
+		}
+		seenKeys[k.ID] = true
+		switch k.Algorithm {
+		case "HS256":
+			if len(k.Secret) < 32 {
+				errs = append(errs, fmt.Errorf("%s.secret 长度不能少于 32 个字符", prefix))
+			}
+		case "RS256", "ES256", "EdDSA":
+			if k.PrivateKeyFile == "" {
+				errs = append(errs, fmt.Errorf("%s.private_key_file 不能为空", prefix))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s.algorithm 仅支持 HS256、RS256、ES256、EdDSA", prefix))
+		}
+	}
+	// 宽限期短于访问令牌有效期会让轮换前签发的令牌提前失效
+	if c.Auth.KeyGracePeriod < c.Auth.AccessTokenTTL {
+		errs = append(errs, errors.New("auth.key_grace_period 不能短于 auth.access_token_ttl"))
+	}
+	if c.Auth.AccessTokenTTL <= 0 {
+		errs = append(errs, errors.New("auth.access_token_ttl 必须大于 0"))
+	}
+	if c.Auth.RefreshTokenTTL <= c.Auth.AccessTokenTTL {
+		errs = append(errs, errors.New("auth.refresh_token_ttl 必须大于 auth.access_token_ttl"))
+	}
+	if c.Auth.PasswordResetTTL <= 0 {
+		errs = append(errs, errors.New("auth.password_reset_ttl 必须大于 0"))
+	}
+	if c.Auth.EmailVerificationTTL <= 0 {
+		errs = append(errs, errors.New("auth.email_verification_ttl 必须大于 0"))
+	}
+	if strings.TrimSpace(c.Auth.TwoFactorIssuer) == "" || strings.Contains(c.Auth.TwoFactorIssuer, ":") {
+		errs = append(errs, errors.New("auth.two_factor_issuer 不能为空且不能包含冒号"))
+	}
+	if c.Auth.TwoFactorChallengeTTL <= 0 {
+		errs = append(errs, errors.New("auth.two_factor_challenge_ttl 必须大于 0"))
+	}
+	if c.Auth.ImpersonationTTL <= 0 || c.Auth.ImpersonationTTL > c.Auth.KeyGracePeriod {
+		errs = append(errs, errors.New("auth.impersonation_ttl 必须大于 0 且不能超过 auth.key_grace_period"))
+	}
+	if c.Auth.Lockout.Store != "database" && c.Auth.Lockout.Store != "memory" {
+		errs = append(errs, errors.New("auth.lockout.store 仅支持 database、memory"))
+	}
+	if c.Auth.Lockout.MaxAttempts <= 0 || c.Auth.Lockout.MaxAttemptsPerIP <= 0 {
+		errs = append(errs, errors.New("auth.lockout.max_attempts 和 auth.lockout.max_attempts_per_ip 必须大于 0"))
+	}
+	if c.Auth.Lockout.BaseDelay < 0 || c.Auth.Lockout.MaxDelay < c.Auth.Lockout.BaseDelay {
+		errs = append(errs, errors.New("auth.lockout.max_delay 不能小于 auth.lockout.base_delay"))
+	}
+	if c.Auth.Lockout.Duration <= 0 {
+		errs = append(errs, errors.New("auth.lockout.duration 必须大于 0"))
+	}
+	if h := c.Auth.PasswordHashing; h.Algorithm != "argon2id" && h.Algorithm != "bcrypt" {
+		errs = append(errs, errors.New("auth.password_hashing.algorithm 仅支持 argon2id、bcrypt"))
+	}
+	if h := c.Auth.PasswordHashing; h.Argon2Memory < 8*1024 || h.Argon2Iterations < 1 || h.Argon2Parallelism < 1 || h.Argon2Parallelism > 255 {
+		errs = append(errs, errors.New("auth.password_hashing 的 argon2 参数无效：内存不少于 8192 KiB，迭代次数至少为 1，并行度在 1 到 255 之间"))
+	}
+	if h := c.Auth.PasswordHashing; h.BcryptCost < 10 || h.BcryptCost > 31 {
+		errs = append(errs, errors.New("auth.password_hashing.bcrypt_cost 必须在 10 到 31 之间"))
+	}
+	if p := c.Auth.PasswordPolicy; p.MinLength < 1 || p.MaxLength < p.MinLength {
+		errs = append(errs, errors.New("auth.password_policy.min_length 至少为 1，且不能大于 max_length"))
+	}
+	if k := c.Auth.APIKeys; k.MaxPerUser <= 0 || k.DefaultTTL < 0 || k.MaxTTL < 0 {
+		errs = append(errs, errors.New("auth.api_keys.max_per_user 必须大于 0，default_ttl 和 max_ttl 不能为负数"))
+	} else if k.MaxTTL > 0 && (k.DefaultTTL == 0 || k.DefaultTTL > k.MaxTTL) {
+		errs = append(errs, errors.New("auth.api_keys.default_ttl 必须在 1 到 max_ttl 之间"))
+	}
+	if c.Auth.OIDC.StateTTL <= 0 {
+		errs = append(errs, errors.New("auth.oidc.state_ttl 必须大于 0"))
+	}
+	seenProviders := make(map[string]bool)
+	for i, p := range c.Auth.OIDC.Providers {
+		prefix := fmt.Sprintf("auth.oidc.providers[%d]", i)
+		if !validProviderName(p.Name) || seenProviders[p.Name] {
+			errs = append(errs, fmt.Errorf("%s.name 只能包含小写字母、数字和连字符，且不能重复", prefix))
+		}
+		seenProviders[p.Name] = true
+		if !strings.HasPrefix(p.Issuer, "https://") && !strings.HasPrefix(p.Issuer, "http://") {
+			errs = append(errs, fmt.Errorf("%s.issuer 必须是 http(s) 地址", prefix))
+		}
+		if p.ClientID == "" {
+			errs = append(errs, fmt.Errorf("%s.client_id 不能为空", prefix))
+		}
+	}
+	if c.Account.DeletionGracePeriod < 0 || c.Account.PurgeInterval < 0 {
+		errs = append(errs, errors.New("account.deletion_grace_period 和 account.purge_interval 不能为负数"))
+	}
+	if c.Account.DeletedContent != "anonymize" && c.Account.DeletedContent != "delete" {
+		errs = append(errs, errors.New("account.deleted_content 仅支持 anonymize、delete"))
+	}
+	switch c.Registration.Mode {
+	case "open", "invite_only", "closed":
+	default:
+		errs = append(errs, errors.New("registration.mode 仅支持 open、invite_only、closed"))
+	}
+	if r := c.Registration; r.InvitationTTL < 0 || r.UserInvitations < 0 || r.UserInvitationMaxUses <= 0 {
+		errs = append(errs, errors.New("registration.invitation_ttl 和 registration.user_invitations 不能为负数，registration.user_invitation_max_uses 必须大于 0"))
+	}
+	if c.Publishing.SchedulerInterval < 0 {
+		errs = append(errs, errors.New("publishing.scheduler_interval 不能为负数"))
+	}
+	if w := c.Publishing.Webhooks; w.Timeout <= 0 || w.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("publishing.webhooks.timeout 和 publishing.webhooks.max_attempts 必须大于 0"))
+	}
+	for i, u := range c.Publishing.Webhooks.URLs {
+		if !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+			errs = append(errs, fmt.Errorf("publishing.webhooks.urls[%d] 必须是 http(s) 地址", i))
+		}
+	}
+	if c.Mail.From == "" {
+		errs = append(errs, errors.New("mail.from 不能为空"))
+	}
+	switch c.Mail.Driver {
+	case "smtp":
+		if c.Mail.SMTP.Host == "" {
+			errs = append(errs, errors.New("mail.smtp.host 不能为空"))
+		}
+	case "file":
+		if c.Mail.Dir == "" {
+			errs = append(errs, errors.New("mail.dir 不能为空"))
+		}
+	case "memory":
+	default:
+		errs = append(errs, errors.New("mail.driver 仅支持 smtp、file、memory"))
+	}
+	return errors.Join(errs...)
+}
+
+// validProxy 可信代理必须是 IP 或 CIDR
+func validProxy(proxy string) bool {
+	if strings.Contains(proxy, "/") {
+		_, _, err := net.ParseCIDR(proxy)
+		return err == nil
+	}
+	return net.ParseIP(proxy) != nil
+}
+
+// validProviderName 身份提供方名称会出现在路由和回调地址中
+func validProviderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// Secret 敏感配置项，格式化输出时始终打码，避免被写入日志
+type Secret string
+
+// String 实现 fmt.Stringer，输出打码后的内容
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "******"
+}
+
+// GoString 实现 fmt.GoStringer，避免 %#v 泄露原文
+func (s Secret) GoString() string {
+	return fmt.Sprintf("config.Secret(%q)", s.String())
+}
+
+// Value 返回密钥原文，仅在真正使用时调用
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// Duration 支持 "72h"、"15m" 这类写法的时长
+type Duration time.Duration
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，供 YAML/TOML 解码使用
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// String 实现 fmt.Stringer
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// Std 转换为 time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
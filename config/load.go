@@ -0,0 +1,216 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix 环境变量前缀
+const EnvPrefix = "REALWORLD_"
+
+// ConfigFileEnv 指定配置文件路径的环境变量
+const ConfigFileEnv = EnvPrefix + "CONFIG"
+
+// field 配置树中的一个叶子字段
+type field struct {
+	key   string // 例如 database.dsn
+	usage string
+	value reflect.Value
+}
+
+// Load 按 默认值 < 配置文件 < 环境变量 < 命令行参数 的优先级加载配置并校验
+// args 一般传 os.Args[1:]，解析后剩余的位置参数通过第二个返回值返回
+func Load(args []string) (*Config, []string, error) {
+	cfg := Default()
+	fields := collectFields(reflect.ValueOf(cfg).Elem(), "")
+
+	fs := flag.NewFlagSet("realworld", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv(ConfigFileEnv), "配置文件路径，支持 .yaml/.yml/.toml")
+	flagValues := make(map[string]string)
+	for _, f := range fields {
+		key := f.key
+		fs.Func(key, f.usage, func(v string) error {
+			flagValues[key] = v
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configPath != "" {
+		if err := loadFile(*configPath, cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, f := range fields {
+		if v, ok := os.LookupEnv(envName(f.key)); ok {
+			if err := setValue(f.value, v); err != nil {
+				return nil, nil, fmt.Errorf("环境变量 %s 无效：%w", envName(f.key), err)
+			}
+		}
+	}
+	for _, f := range fields {
+		if v, ok := flagValues[f.key]; ok {
+			if err := setValue(f.value, v); err != nil {
+				return nil, nil, fmt.Errorf("命令行参数 -%s 无效：%w", f.key, err)
+			}
+		}
+	}
+
+	if err := cfg.resolveSecretFiles(); err != nil {
+		return nil, nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// loadFile 根据扩展名解析 YAML 或 TOML 配置文件
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败：%w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("不支持的配置文件格式：%s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("解析配置文件 %s 失败：%w", path, err)
+	}
+	return nil
+}
+
+// resolveSecretFiles 从 *_file 指定的文件中读取密钥，文件优先于明文配置
+func (c *Config) resolveSecretFiles() error {
+	secrets := []struct {
+		path   string
+		target *Secret
+	}{
+		{c.Database.DSNFile, &c.Database.DSN},
+		{c.Auth.SecretFile, &c.Auth.Secret},
+		{c.Mail.SMTP.PasswordFile, &c.Mail.SMTP.Password},
+		{c.Publishing.Webhooks.SecretFile, &c.Publishing.Webhooks.Secret},
+	}
+	for i := range c.Auth.SigningKeys {
+		k := &c.Auth.SigningKeys[i]
+		secrets = append(secrets, struct {
+			path   string
+			target *Secret
+		}{k.SecretFile, &k.Secret})
+	}
+	for i := range c.Auth.OIDC.Providers {
+		p := &c.Auth.OIDC.Providers[i]
+		secrets = append(secrets, struct {
+			path   string
+			target *Secret
+		}{p.ClientSecretFile, &p.ClientSecret})
+	}
+	for _, s := range secrets {
+		if s.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			return fmt.Errorf("读取密钥文件失败：%w", err)
+		}
+		*s.target = Secret(strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// collectFields 递归收集所有叶子字段，键名取自 yaml 标签
+func collectFields(v reflect.Value, prefix string) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+			fields = append(fields, collectFields(fv, key)...)
+			continue
+		}
+		// 结构体列表无法用单个字符串表示，只能在配置文件中设置
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Struct {
+			continue
+		}
+		fields = append(fields, field{key: key, usage: sf.Tag.Get("usage"), value: fv})
+	}
+	return fields
+}
+
+// envName database.dsn_file -> REALWORLD_DATABASE_DSN_FILE
+func envName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+var (
+	durationType = reflect.TypeOf(Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// setValue 把字符串形式的配置写入字段
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return errors.New("仅支持字符串列表")
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("不支持的配置类型：%s", v.Type())
+	}
+	return nil
+}
@@ -1,22 +1,18 @@
 package config
 
 import (
-	_ "github.com/go-sql-driver/mysql"
-	"goDemo/models"
-	"gorm.io/driver/mysql"
+	"goDemo/dialect"
 	"gorm.io/gorm"
 )
 
-// InitDB 初始化数据库连接
-func InitDB() (*gorm.DB, error) {
-	dsh := "root:740627119zxl@tcp(127.0.0.1:3306)/realworld_sql?charset=utf8&parseTime=true"
-	db, err := gorm.Open(mysql.Open(dsh), &gorm.Config{})
+// InitDB 根据配置的数据库类型初始化数据库连接
+// 表结构由 migrations 包管理，这里不再执行 AutoMigrate
+func InitDB(cfg DatabaseConfig) (*gorm.DB, error) {
+	d, err := dialect.Lookup(cfg.Driver)
 	if err != nil {
 		return nil, err
 	}
-	err = db.AutoMigrate(&models.UserModel{}, &models.Follow{},
-		&models.Article{}, &models.Comment{}, &models.Favorite{})
-
+	db, err := gorm.Open(d.Open(cfg.DSN.Value()), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"goDemo/models"
+	"goDemo/service"
+	"goDemo/utils"
+	"net/http"
+	"time"
+)
+
+type AccountController struct {
+	AccountService *service.AccountService
+}
+
+// ExportData godoc
+// @Summary 导出个人数据
+// @Description 下载 zip 归档：data.json 包含资料、文章、评论、收藏、关注、会话、API 密钥和关联账号，articles/ 下为每篇文章的 Markdown，comments.md 汇总评论
+// @Tags account
+// @Produce  application/zip
+// @Security BearerAuth
+// @Success 200 {file} file "个人数据归档"
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "不能使用 API 密钥导出"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/export [get]
+func (c *AccountController) ExportData(ctx *gin.Context) {
+	user := utils.CurrentUser(ctx)
+	// 先写入内存，生成失败时还能返回 JSON 错误
+	var archive bytes.Buffer
+	if err := c.AccountService.WriteArchive(&archive, user); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	filename := fmt.Sprintf("realworld-export-%s-%s.zip", user.Username, time.Now().UTC().Format("20060102"))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+// DeleteAccount godoc
+// @Summary 申请注销账号
+// @Description 账号在宽限期结束后清理，期间可以正常登录并撤销；清理时按配置匿名化或删除发布的文章和评论，关注、收藏和登录凭据一并删除
+// @Tags account
+// @Produce  json
+// @Security BearerAuth
+// @Success 202 {object} models.AccountDeletionResponse
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "不能使用 API 密钥注销"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user [delete]
+func (c *AccountController) DeleteAccount(ctx *gin.Context) {
+	scheduledAt, err := c.AccountService.ScheduleDeletion(utils.CurrentUser(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, models.AccountDeletionResponse{DeletionScheduledAt: scheduledAt})
+}
+
+// CancelDeletion godoc
+// @Summary 撤销注销申请
+// @Tags account
+// @Produce  json
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "不能使用 API 密钥撤销"
+// @Failure 409 {object} map[string]interface{} "账号没有申请注销"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/deletion/cancel [post]
+func (c *AccountController) CancelDeletion(ctx *gin.Context) {
+	if err := c.AccountService.CancelDeletion(utils.CurrentUser(ctx)); err != nil {
+		if errors.Is(err, service.ErrDeletionNotScheduled) {
+			ctx.JSON(http.StatusConflict, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
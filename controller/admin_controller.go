@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"goDemo/models"
+	"goDemo/policy"
+	"goDemo/service"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AdminController struct {
+	ProfileService       *service.ProfileService
+	ImpersonationService *service.ImpersonationService
+}
+
+// ListUsers godoc
+// @Summary 用户角色列表
+// @Description 管理员查看用户及其角色，可按角色筛选
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   role query string false "角色：user、moderator、admin"
+// @Success 200 {object} models.UserRolesResponse "用户角色列表"
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "无权访问"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/admin/users [get]
+func (c *AdminController) ListUsers(ctx *gin.Context) {
+	users, err := c.ProfileService.ListUsersWithRoles(utils.CurrentUser(ctx), ctx.Query("role"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	response := models.UserRolesResponse{Users: []models.UserRoleResponse{}}
+	for _, user := range users {
+		response.Users = append(response.Users, models.UserRoleResponse{
+			Username:  user.Username,
+			Email:     user.Email,
+			Role:      user.Role,
+			CreatedAt: user.CreatedAt,
+		})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateRole godoc
+// @Summary 修改用户角色
+// @Description 管理员为用户分配 user、moderator 或 admin 角色
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   username path string true "用户名"
+// @Param   body body models.UpdateRoleRequest true "新角色"
+// @Success 200 {object} models.UserRoleResponse "修改成功"
+// @Failure 400 {object} map[string]interface{} "角色无效或修改自己的角色"
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "无权访问"
+// @Failure 404 {object} map[string]interface{} "用户不存在"
+// @Router /api/admin/users/{username}/role [put]
+func (c *AdminController) UpdateRole(ctx *gin.Context) {
+	var request models.UpdateRoleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	user, err := c.ProfileService.UpdateRole(utils.CurrentUser(ctx), ctx.Param("username"), request.Role)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"用户不存在"}}})
+		} else if errors.Is(err, service.ErrInvalidRole) || errors.Is(err, service.ErrChangeOwnRole) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, models.UserRoleResponse{
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+	})
+}
+
+// UnlockUser godoc
+// @Summary 解除登录锁定
+// @Description 清除用户账号的登录失败记录，立即允许再次登录
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   username path string true "用户名"
+// @Success 204 "已解锁"
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "无权访问"
+// @Failure 404 {object} map[string]interface{} "用户不存在"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/admin/users/{username}/unlock [post]
+func (c *AdminController) UnlockUser(ctx *gin.Context) {
+	_, err := c.ProfileService.UnlockUser(utils.CurrentUser(ctx), ctx.Param("username"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"用户不存在"}}})
+		} else if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// Impersonate godoc
+// @Summary 代入用户身份
+// @Description 为排查问题签发限时的代入令牌，以被代入用户的身份访问文章、评论、资料等接口；不能访问账号设置和管理后台，也不能代入其他管理员。发起、结束和代入期间的写请求都会记入审计日志
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   username path string true "被代入的用户名"
+// @Param   body body models.ImpersonateRequest true "代入原因"
+// @Success 201 {object} models.ImpersonationResponse
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "无权访问或不能代入该用户"
+// @Failure 404 {object} map[string]interface{} "用户不存在"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/admin/users/{username}/impersonate [post]
+func (c *AdminController) Impersonate(ctx *gin.Context) {
+	var request models.ImpersonateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	impersonation, token, err := c.ImpersonationService.Start(utils.CurrentUser(ctx), ctx.Param("username"), request.Reason, ctx.ClientIP())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"用户不存在"}}})
+		} else if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.ImpersonationResponse{Impersonation: impersonation.View(time.Now()), Token: token})
+}
+
+// ListImpersonations godoc
+// @Summary 代入记录
+// @Description 最近 100 条代入记录，active=true 时只返回进行中的
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param   active query bool false "只返回进行中的代入"
+// @Success 200 {object} models.ImpersonationsResponse
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "无权访问"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/admin/impersonations [get]
+func (c *AdminController) ListImpersonations(ctx *gin.Context) {
+	impersonations, err := c.ImpersonationService.List(ctx.Query("active") == "true")
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	now := time.Now()
+	response := models.ImpersonationsResponse{Impersonations: []models.ImpersonationView{}}
+	for i := range impersonations {
+		response.Impersonations = append(response.Impersonations, impersonations[i].View(now))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// EndImpersonation godoc
+// @Summary 结束代入
+// @Description 立即吊销代入令牌
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param   id path int true "代入记录 ID"
+// @Success 200 {object} models.ImpersonationResponse
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "无权访问"
+// @Failure 404 {object} map[string]interface{} "代入记录不存在"
+// @Failure 409 {object} map[string]interface{} "代入已结束"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/admin/impersonations/{id} [delete]
+func (c *AdminController) EndImpersonation(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{service.ErrImpersonationNotFound.Error()}}})
+		return
+	}
+	impersonation, err := c.ImpersonationService.End(utils.CurrentUser(ctx), uint(id), ctx.ClientIP())
+	if err != nil {
+		if errors.Is(err, service.ErrImpersonationNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else if errors.Is(err, service.ErrImpersonationEnded) {
+			ctx.JSON(http.StatusConflict, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, models.ImpersonationResponse{Impersonation: impersonation.View(time.Now())})
+}
+
+// ListAuditLog godoc
+// @Summary 审计日志
+// @Description 代入的发起、结束以及代入期间的写请求，最新的在前
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param   action query string false "操作：impersonation.start、impersonation.end、impersonation.request"
+// @Param   actor query string false "发起代入的管理员用户名"
+// @Param   subject query string false "被代入的用户名"
+// @Param   limit query int false "每页数量"
+// @Param   offset query int false "偏移量"
+// @Success 200 {object} models.AuditLogResponse
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "无权访问"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/admin/audit-log [get]
+func (c *AdminController) ListAuditLog(ctx *gin.Context) {
+	entries, count, err := c.ImpersonationService.AuditLog(service.AuditLogParams{
+		Action:  ctx.Query("action"),
+		Actor:   ctx.Query("actor"),
+		Subject: ctx.Query("subject"),
+		Limit:   getIntQuery(ctx, "limit", 20),
+		Offset:  getIntQuery(ctx, "offset", 0),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	response := models.AuditLogResponse{Entries: []models.AuditLogView{}, EntryCount: count}
+	for i := range entries {
+		response.Entries = append(response.Entries, entries[i].View())
+	}
+	ctx.JSON(http.StatusOK, response)
+}
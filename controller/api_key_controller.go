@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"goDemo/models"
+	"goDemo/policy"
+	"goDemo/service"
+	"goDemo/utils"
+	"net/http"
+)
+
+type APIKeyController struct {
+	APIKeyService *service.APIKeyService
+}
+
+// CreateAPIKey godoc
+// @Summary 创建 API 密钥
+// @Description 创建带授权范围和过期时间的个人 API 密钥，调用接口时使用 "Authorization: Token <key>"。密钥原文只在创建时返回一次
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   request body models.APIKeyRequest true "名称、授权范围（如 articles:write、comments:read）和过期时间"
+// @Success 201 {object} models.APIKeyResponse
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "不能使用 API 密钥创建密钥"
+// @Failure 409 {object} map[string]interface{} "密钥数量已达上限"
+// @Failure 422 {object} map[string]interface{} "授权范围或过期时间无效"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/api-keys [post]
+func (c *APIKeyController) CreateAPIKey(ctx *gin.Context) {
+	var request models.APIKeyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	key, rawKey, err := c.APIKeyService.Create(utils.CurrentUser(ctx), &request)
+	if err != nil {
+		switch {
+		case errors.Is(err, policy.ErrUnknownScope), errors.Is(err, service.ErrInvalidAPIKeyExpiry):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		case errors.Is(err, service.ErrTooManyAPIKeys):
+			ctx.JSON(http.StatusConflict, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.APIKeyResponse{APIKey: key.View(), Key: rawKey})
+}
+
+// ListAPIKeys godoc
+// @Summary API 密钥列表
+// @Description 列出当前用户未过期、未吊销的 API 密钥及其最近使用时间，不包含密钥原文
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.APIKeysResponse
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/api-keys [get]
+func (c *APIKeyController) ListAPIKeys(ctx *gin.Context) {
+	keys, err := c.APIKeyService.List(utils.CurrentUser(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	response := models.APIKeysResponse{APIKeys: []models.APIKeyView{}}
+	for i := range keys {
+		response.APIKeys = append(response.APIKeys, keys[i].View())
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RevokeAPIKey godoc
+// @Summary 吊销 API 密钥
+// @Description 吊销当前用户的 API 密钥，立即生效
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   id path int true "密钥 ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{} "无效的密钥 ID"
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 404 {object} map[string]interface{} "密钥不存在"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/api-keys/{id} [delete]
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context) {
+	var id uint
+	if _, err := fmt.Sscanf(ctx.Param("id"), "%d", &id); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"无效的密钥 ID"}}})
+		return
+	}
+	if err := c.APIKeyService.Revoke(utils.CurrentUser(ctx), id); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -5,17 +5,16 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"goDemo/models"
+	"goDemo/policy"
 	"goDemo/service"
 	"goDemo/utils"
-	"gorm.io/gorm"
 	"net/http"
-	"strings"
+	"net/url"
 	"time"
 )
 
 type ArticleController struct {
 	ArticleService *service.ArticleService
-	Auth           *utils.Auth
 }
 
 // ListArticles 文章列表
@@ -25,7 +24,7 @@ type ArticleController struct {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param tag query string false "标签"
+// @Param tag query string false "标签，按规范化后的名称匹配，不区分大小写"
 // @Param author query string false "作者"
 // @Param favorited query string false "是否收藏"
 // @Param limit query int false "每页数量"
@@ -40,12 +39,8 @@ func (c *ArticleController) ListArticles(ctx *gin.Context) {
 		Limit:     getIntQuery(ctx, "limit", 20),
 		Offset:    getIntQuery(ctx, "offset", 0),
 	}
-	// 从认证信息中获取用户 ID
-	userID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		// 处理未认证情况
-		userID = 0
-	}
+	// 从认证信息中获取用户 ID，未登录时为 0
+	userID := utils.CurrentUserID(ctx)
 	articles, count, err := c.ArticleService.ListArticles(userID, param)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
@@ -69,16 +64,49 @@ func (c *ArticleController) ListArticles(ctx *gin.Context) {
 // @Success 200 {object} models.ArticleListResponse
 // @Router /api/articles/feed [get]
 func (c *ArticleController) FeedArticles(ctx *gin.Context) {
-	userID, err := c.Auth.ParseToken(ctx)
+	userID := utils.CurrentUserID(ctx)
+	param := service.FeedArticlesParams{
+		Limit:  getIntQuery(ctx, "limit", 20),
+		Offset: getIntQuery(ctx, "offset", 0),
+	}
+	articles, count, err := c.ArticleService.FeedArticles(userID, param)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		return
 	}
-	param := service.FeedArticlesParams{
+	ctx.JSON(http.StatusOK, models.ArticleListResponse{
+		Articles:      articles,
+		ArticlesCount: int(count),
+	})
+}
+
+// ListUserArticles 我的文章
+// @Summary 我的文章
+// @Description 列出当前用户的所有文章，包括草稿，最近修改的在前
+// @Tags articles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "文章状态：draft、scheduled、published、archived、unlisted"
+// @Param limit query int false "每页数量"
+// @Param offset query int false "偏移量"
+// @Success 200 {object} models.ArticleListResponse
+// @Failure 400 {object} map[string]interface{} "无效的文章状态"
+// @Router /api/user/articles [get]
+func (c *ArticleController) ListUserArticles(ctx *gin.Context) {
+	status := ctx.Query("status")
+	switch status {
+	case "", models.ArticleDraft, models.ArticlePublished, models.ArticleArchived, models.ArticleUnlisted, models.ArticleScheduled:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"无效的文章状态"}}})
+		return
+	}
+	param := service.UserArticlesParams{
+		Status: status,
 		Limit:  getIntQuery(ctx, "limit", 20),
 		Offset: getIntQuery(ctx, "offset", 0),
 	}
-	articles, count, err := c.ArticleService.FeedArticles(userID, param)
+	articles, count, err := c.ArticleService.UserArticles(utils.CurrentUser(ctx), param)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		return
@@ -91,17 +119,19 @@ func (c *ArticleController) FeedArticles(ctx *gin.Context) {
 
 // GetArticle 获取文章
 // @Summary 获取文章
-// @Description 获取文章详情
+// @Description 获取文章详情，草稿只有作者可以查看。使用文章的旧 slug 时永久重定向到当前 slug
 // @Tags articles
 // @Accept json
 // @Produce json
 // @Param slug path string true "文章slug"
 // @Success 200 {object} models.ArticleResponse
+// @Success 301 {string} string "旧 slug，Location 为文章的当前地址"
+// @Failure 404 {object} map[string]interface{} "文章没找到"
 // @Router /api/articles/{slug} [get]
 func (c *ArticleController) GetArticle(ctx *gin.Context) {
 
 	slug := ctx.Param("slug")
-	article, err := c.ArticleService.GetArticle(slug)
+	article, err := c.ArticleService.GetArticle(utils.CurrentUser(ctx), slug)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		return
@@ -110,27 +140,32 @@ func (c *ArticleController) GetArticle(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"文章没找到哦"}}})
 		return
 	}
+	if article.Slug != slug {
+		location := "/api/articles/" + url.PathEscape(article.Slug)
+		if ctx.Request.URL.RawQuery != "" {
+			location += "?" + ctx.Request.URL.RawQuery
+		}
+		ctx.Redirect(http.StatusMovedPermanently, location)
+		return
+	}
 	ctx.JSON(http.StatusOK, models.ArticleResponse{Article: *article})
 }
 
 // CreateArticle 创建文章
 // @Summary 创建文章
-// @Description 创建新文章
+// @Description 创建新文章，status 为 draft 时保存为草稿，指定 publishAt 时到期自动发布，默认直接发布。标签会被规范化（NFKC、合并空白、转小写）并去重。指定 slug 时使用并固定该 slug，否则根据标题生成，与已有文章重复时自动加后缀
 // @Tags articles
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param article body models.CreateArticleRequest true "文章信息"
 // @Success 201 {object} models.ArticleResponse
+// @Failure 409 {object} map[string]interface{} "slug 已被使用"
+// @Failure 422 {object} map[string]interface{} "定时发布时间、slug 或标签无效"
 // @Router /api/articles [post]
 func (c *ArticleController) CreateArticle(ctx *gin.Context) {
 
-	//校验token
-	userID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
-		return
-	}
+	actor := utils.CurrentUser(ctx)
 
 	//解析请求内容
 	var request models.CreateArticleRequest
@@ -140,9 +175,21 @@ func (c *ArticleController) CreateArticle(ctx *gin.Context) {
 	}
 
 	//调用服务层创建文章
-	article, err := c.ArticleService.CreateArticle(userID, request)
+	article, err := c.ArticleService.CreateArticle(actor, request)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{"无权发布文章"}}})
+		} else if errors.Is(err, service.ErrSlugTaken) {
+			ctx.JSON(http.StatusConflict, gin.H{"errors": gin.H{"slug": []string{err.Error()}}})
+		} else if errors.Is(err, service.ErrInvalidSlug) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"slug": []string{err.Error()}}})
+		} else if errors.Is(err, service.ErrInvalidTag) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"tagList": []string{err.Error()}}})
+		} else if errors.Is(err, service.ErrInvalidPublishAt) || errors.Is(err, service.ErrPublishAtWithStatus) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"publishAt": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
 		return
 	}
 
@@ -152,7 +199,7 @@ func (c *ArticleController) CreateArticle(ctx *gin.Context) {
 
 // UpdateArticle 更新文章
 // @Summary 更新文章
-// @Description 更新文章信息
+// @Description 更新文章信息，也可以通过 status 修改文章状态，或通过 publishAt 定时发布未发布的文章。修改标题时未固定的 slug 随标题变化；slug 传自定义值时固定，传空字符串取消固定。原 slug 仍指向该文章
 // @Tags articles
 // @Accept json
 // @Produce json
@@ -160,14 +207,12 @@ func (c *ArticleController) CreateArticle(ctx *gin.Context) {
 // @Param slug path string true "文章slug"
 // @Param article body models.UpdateArticleRequest true "文章信息"
 // @Success 200 {object} models.ArticleResponse
+// @Failure 403 {object} map[string]interface{} "无权更新该文章，或邮箱未验证时发布、定时发布文章"
+// @Failure 409 {object} map[string]interface{} "文章已发布，不能定时发布，或 slug 已被使用"
+// @Failure 422 {object} map[string]interface{} "定时发布时间或 slug 无效"
 // @Router /api/articles/{slug} [put]
 func (c *ArticleController) UpdateArticle(ctx *gin.Context) {
-	//校验token
-	userID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
-		return
-	}
+	actor := utils.CurrentUser(ctx)
 	//解析请求内容并调用服务层更新文章
 	slug := ctx.Param("slug")
 	var request models.UpdateArticleRequest
@@ -175,11 +220,23 @@ func (c *ArticleController) UpdateArticle(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		return
 	}
-	article, err := c.ArticleService.UpdateArticle(userID, slug, request)
+	article, err := c.ArticleService.UpdateArticle(actor, slug, request)
 	//处理错误
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"文章没找到或无权查看"}}})
+		if errors.Is(err, service.ErrArticleNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{"无权更新该文章"}}})
+		} else if errors.Is(err, policy.ErrEmailNotVerified) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else if errors.Is(err, service.ErrArticleAlreadyPublished) {
+			ctx.JSON(http.StatusConflict, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else if errors.Is(err, service.ErrSlugTaken) {
+			ctx.JSON(http.StatusConflict, gin.H{"errors": gin.H{"slug": []string{err.Error()}}})
+		} else if errors.Is(err, service.ErrInvalidSlug) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"slug": []string{err.Error()}}})
+		} else if errors.Is(err, service.ErrInvalidPublishAt) || errors.Is(err, service.ErrPublishAtWithStatus) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"publishAt": []string{err.Error()}}})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		}
@@ -189,6 +246,53 @@ func (c *ArticleController) UpdateArticle(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, models.ArticleResponse{Article: *article})
 }
 
+// PublishArticle 发布文章
+// @Summary 发布文章
+// @Description 立即发布草稿或定时发布的文章，或重新发布已归档、不公开列出的文章，首次发布时记录发布时间
+// @Tags articles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "文章slug"
+// @Success 200 {object} models.ArticleResponse
+// @Failure 403 {object} map[string]interface{} "无权修改该文章"
+// @Failure 404 {object} map[string]interface{} "文章没找到"
+// @Router /api/articles/{slug}/publish [post]
+func (c *ArticleController) PublishArticle(ctx *gin.Context) {
+	c.setStatus(ctx, models.ArticlePublished)
+}
+
+// UnpublishArticle 取消发布文章
+// @Summary 取消发布文章
+// @Description 把文章改回草稿并取消定时发布，不再出现在列表中，只有作者可以查看
+// @Tags articles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "文章slug"
+// @Success 200 {object} models.ArticleResponse
+// @Failure 403 {object} map[string]interface{} "无权修改该文章"
+// @Failure 404 {object} map[string]interface{} "文章没找到"
+// @Router /api/articles/{slug}/publish [delete]
+func (c *ArticleController) UnpublishArticle(ctx *gin.Context) {
+	c.setStatus(ctx, models.ArticleDraft)
+}
+
+func (c *ArticleController) setStatus(ctx *gin.Context, status string) {
+	article, err := c.ArticleService.SetStatus(utils.CurrentUser(ctx), ctx.Param("slug"), status)
+	if err != nil {
+		if errors.Is(err, service.ErrArticleNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{"无权修改该文章"}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, models.ArticleResponse{Article: *article})
+}
+
 // DeleteArticle 删除文章
 // @Summary 删除文章
 // @Description 删除指定文章
@@ -200,19 +304,16 @@ func (c *ArticleController) UpdateArticle(ctx *gin.Context) {
 // @Success 204 {object} models.ArticleResponse
 // @Router /api/articles/{slug} [delete]
 func (c *ArticleController) DeleteArticle(ctx *gin.Context) {
-	//校验token
-	userID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
-		return
-	}
+	actor := utils.CurrentUser(ctx)
 	//调用服务层删除文章
 	slug := ctx.Param("slug")
-	err = c.ArticleService.DeleteArticle(userID, slug)
+	err := c.ArticleService.DeleteArticle(actor, slug)
 	//处理错误
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"文章未找到或无权限删除"}}})
+		if errors.Is(err, service.ErrArticleNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{"无权删除该文章"}}})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		}
@@ -233,29 +334,27 @@ func (c *ArticleController) DeleteArticle(ctx *gin.Context) {
 // @Success 201 {object} models.CommentResponse
 // @Router /api/articles/{slug}/comments [post]
 func (c *ArticleController) AddComment(ctx *gin.Context) {
-	//校验token
-	userID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
-		return
-	}
+	actor := utils.CurrentUser(ctx)
 	slug := ctx.Param("slug")
 	var request models.CreateCommentRequest
 	if err := ctx.ShouldBind(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
 	}
 	//创建评论并返回信息
-	comment, err := c.ArticleService.CreateComment(userID, slug, request)
+	comment, err := c.ArticleService.CreateComment(actor, slug, request)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, service.ErrArticleNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"文章未找到"}}})
+		} else if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{"无权评论该文章"}}})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		}
 		return
 	}
 	//是否已关注该评论作者
-	isFollowing, err := c.ArticleService.IsFollowing(userID, comment.AuthorID)
+	isFollowing, err := c.ArticleService.IsFollowing(actor.ID, comment.AuthorID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		return
@@ -305,16 +404,14 @@ func (c *ArticleController) AddComment(ctx *gin.Context) {
 // @Success 200 {object} models.CommentsResponse
 // @Router /api/articles/{slug}/comments [get]
 func (c *ArticleController) GetComments(ctx *gin.Context) {
-	// 校验token
-	userID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
-		return
-	}
 	slug := ctx.Param("slug")
-	commentResponses, err := c.ArticleService.GetCommentsBySlug(slug, userID)
+	commentResponses, err := c.ArticleService.GetCommentsBySlug(utils.CurrentUser(ctx), slug)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		if errors.Is(err, service.ErrArticleNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
 		return
 	}
 	// 构造响应
@@ -337,24 +434,21 @@ func (c *ArticleController) GetComments(ctx *gin.Context) {
 // @Success 204 {object} models.CommentResponse
 // @Router /api/articles/{slug}/comments/{id} [delete]
 func (c *ArticleController) DeleteComment(ctx *gin.Context) {
-	// 校验token
-	userID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
-		return
-	}
+	actor := utils.CurrentUser(ctx)
 	slug := ctx.Param("slug")
 	commentIDStr := ctx.Param("id")
 	var commentID uint
-	_, err = fmt.Sscanf(commentIDStr, "%d", &commentID)
+	_, err := fmt.Sscanf(commentIDStr, "%d", &commentID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"无效的评论 ID"}}})
 		return
 	}
-	err = c.ArticleService.DeleteComment(userID, slug, commentID)
+	err = c.ArticleService.DeleteComment(actor, slug, commentID)
 	if err != nil {
-		if strings.Contains(err.Error(), "评论未找到") {
+		if errors.Is(err, service.ErrArticleNotFound) || errors.Is(err, service.ErrCommentNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{"无权删除该评论"}}})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		}
@@ -374,17 +468,14 @@ func (c *ArticleController) DeleteComment(ctx *gin.Context) {
 // @Success 200 {object} models.ArticleResponse
 // @Router /api/articles/{slug}/favorite [post]
 func (c *ArticleController) FavoriteArticle(ctx *gin.Context) {
-	// 校验token
-	userID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
-		return
-	}
+	actor := utils.CurrentUser(ctx)
 	slug := ctx.Param("slug")
-	article, err := c.ArticleService.FavoriteArticle(userID, slug)
+	article, err := c.ArticleService.FavoriteArticle(actor, slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, service.ErrArticleNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"文章未找到"}}})
+		} else if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{"无权收藏该文章"}}})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		}
@@ -404,17 +495,14 @@ func (c *ArticleController) FavoriteArticle(ctx *gin.Context) {
 // @Success 200 {object} models.ArticleResponse
 // @Router /api/articles/{slug}/favorite [delete]
 func (c *ArticleController) UnfavoriteArticle(ctx *gin.Context) {
-	// 校验token
-	userID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
-		return
-	}
+	actor := utils.CurrentUser(ctx)
 	slug := ctx.Param("slug")
-	article, err := c.ArticleService.UnfavoriteArticle(userID, slug)
+	article, err := c.ArticleService.UnfavoriteArticle(actor, slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, service.ErrArticleNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"文章未找到"}}})
+		} else if errors.Is(err, policy.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{"无权取消收藏该文章"}}})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"goDemo/models"
+	"goDemo/service"
+	"goDemo/utils"
+	"net/http"
+	"time"
+)
+
+type InvitationController struct {
+	InvitationService *service.InvitationService
+}
+
+// Registration godoc
+// @Summary 注册方式
+// @Description 返回当前的注册方式：open、invite_only 或 closed
+// @Tags invitations
+// @Produce  json
+// @Success 200 {object} models.RegistrationResponse
+// @Router /api/users/registration [get]
+func (c *InvitationController) Registration(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.InvitationService.Registration())
+}
+
+// CreateInvitation godoc
+// @Summary 创建邀请码
+// @Description 普通用户受有效邀请码数量、使用次数和有效期上限的限制，管理员不受限制。邀请码原文只在创建时返回一次
+// @Tags invitations
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   request body models.InvitationRequest true "使用次数、过期时间和备注"
+// @Success 201 {object} models.InvitationResponse
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "只有管理员可以创建邀请码"
+// @Failure 409 {object} map[string]interface{} "邀请码数量已达上限"
+// @Failure 422 {object} map[string]interface{} "使用次数或过期时间超出上限"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/invitations [post]
+func (c *InvitationController) CreateInvitation(ctx *gin.Context) {
+	var request models.InvitationRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	invitation, code, err := c.InvitationService.Create(utils.CurrentUser(ctx), &request)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvitationsDisabled):
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		case errors.Is(err, service.ErrTooManyInvitations):
+			ctx.JSON(http.StatusConflict, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		case errors.Is(err, service.ErrInvitationMaxUses), errors.Is(err, service.ErrInvalidInvitationExpiry):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.InvitationResponse{Invitation: invitation.View(time.Now()), Code: code})
+}
+
+// ListInvitations godoc
+// @Summary 我的邀请码
+// @Description 列出当前用户创建的邀请码（包括已失效的）以及通过它们注册的用户，不包含邀请码原文
+// @Tags invitations
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.InvitationsResponse
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/invitations [get]
+func (c *InvitationController) ListInvitations(ctx *gin.Context) {
+	invitations, err := c.InvitationService.ListByInviter(utils.CurrentUser(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	ctx.JSON(http.StatusOK, newInvitationsResponse(invitations, int64(len(invitations))))
+}
+
+// RevokeInvitation godoc
+// @Summary 吊销邀请码
+// @Description 创建者可以吊销自己的邀请码，管理员可以吊销任何邀请码；已注册的账号不受影响
+// @Tags invitations
+// @Produce  json
+// @Security BearerAuth
+// @Param   id path int true "邀请码 ID"
+// @Success 200 {object} models.InvitationResponse
+// @Failure 400 {object} map[string]interface{} "无效的邀请码 ID"
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 404 {object} map[string]interface{} "邀请码不存在"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/invitations/{id} [delete]
+func (c *InvitationController) RevokeInvitation(ctx *gin.Context) {
+	var id uint
+	if _, err := fmt.Sscanf(ctx.Param("id"), "%d", &id); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"无效的邀请码 ID"}}})
+		return
+	}
+	invitation, err := c.InvitationService.Revoke(utils.CurrentUser(ctx), id)
+	if err != nil {
+		if errors.Is(err, service.ErrInvitationNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, models.InvitationResponse{Invitation: invitation.View(time.Now())})
+}
+
+// ListAllInvitations godoc
+// @Summary 所有邀请码
+// @Description 管理员查看所有用户创建的邀请码以及邀请关系
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param   active query bool false "只返回仍可使用的邀请码"
+// @Param   limit query int false "每页数量"
+// @Param   offset query int false "偏移量"
+// @Success 200 {object} models.InvitationsResponse
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 403 {object} map[string]interface{} "无权访问"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/admin/invitations [get]
+func (c *InvitationController) ListAllInvitations(ctx *gin.Context) {
+	invitations, count, err := c.InvitationService.List(ctx.Query("active") == "true", getIntQuery(ctx, "limit", 20), getIntQuery(ctx, "offset", 0))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	ctx.JSON(http.StatusOK, newInvitationsResponse(invitations, count))
+}
+
+func newInvitationsResponse(invitations []models.Invitation, count int64) models.InvitationsResponse {
+	now := time.Now()
+	response := models.InvitationsResponse{Invitations: []models.InvitationView{}, InvitationsCount: count}
+	for i := range invitations {
+		response.Invitations = append(response.Invitations, invitations[i].View(now))
+	}
+	return response
+}
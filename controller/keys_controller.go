@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"goDemo/utils"
+	"net/http"
+	"time"
+)
+
+type KeysController struct {
+	Auth *utils.Auth
+}
+
+// JWKS godoc
+// @Summary 获取签名公钥
+// @Description 以 JWK Set 格式返回当前可用于验证以及即将生效的非对称签名公钥，HS256 密钥不会公开
+// @Tags keys
+// @Produce  json
+// @Success 200 {object} utils.JWKSet "公钥列表"
+// @Router /.well-known/jwks.json [get]
+func (c *KeysController) JWKS(ctx *gin.Context) {
+	// 允许短时间缓存，新密钥会在生效前提前发布
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.Auth.Keys.JWKS(time.Now()))
+}
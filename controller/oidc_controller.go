@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"goDemo/models"
+	"goDemo/oidc"
+	"goDemo/service"
+	"goDemo/utils"
+	"net/http"
+)
+
+type OIDCController struct {
+	OIDCService      *service.OIDCService
+	TokenService     *service.TokenService
+	TwoFactorService *service.TwoFactorService
+}
+
+// ListProviders godoc
+// @Summary 第三方登录方式
+// @Description 列出已配置的 OpenID Connect 身份提供方，用于展示“使用 X 登录”按钮
+// @Tags oidc
+// @Produce  json
+// @Success 200 {object} models.OIDCProvidersResponse
+// @Router /api/users/oidc/providers [get]
+func (c *OIDCController) ListProviders(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, models.OIDCProvidersResponse{Providers: c.OIDCService.ListProviders()})
+}
+
+// Authorize godoc
+// @Summary 发起第三方登录
+// @Description 返回身份提供方的授权地址（授权码 + PKCE），前端跳转过去；已登录时发起的是关联账号
+// @Tags oidc
+// @Produce  json
+// @Security BearerAuth
+// @Param   provider path string true "身份提供方名称"
+// @Success 200 {object} models.OIDCAuthorizeResponse
+// @Failure 401 {object} map[string]interface{} "携带了无效的令牌"
+// @Failure 404 {object} map[string]interface{} "未配置该登录方式"
+// @Failure 502 {object} map[string]interface{} "身份提供方不可用"
+// @Router /api/users/oidc/{provider}/authorize [post]
+func (c *OIDCController) Authorize(ctx *gin.Context) {
+	authURL, err := c.OIDCService.Authorize(ctx.Request.Context(), ctx.Param("provider"), utils.CurrentUser(ctx))
+	if err != nil {
+		respondOIDCError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.OIDCAuthorizeResponse{AuthorizationURL: authURL})
+}
+
+// Callback godoc
+// @Summary 完成第三方登录
+// @Description 提交身份提供方回调地址上的 code 和 state。首次登录时创建账号；开启了两步验证时返回挑战令牌；关联账号时返回已关联的身份列表
+// @Tags oidc
+// @Accept  json
+// @Produce  json
+// @Param   provider path string true "身份提供方名称"
+// @Param   request body models.OIDCCallbackRequest true "回调参数"
+// @Success 200 {object} models.UserResponse "登录成功"
+// @Failure 400 {object} map[string]interface{} "state 无效或已过期"
+// @Failure 401 {object} map[string]interface{} "授权码或 ID Token 校验失败"
+// @Failure 403 {object} map[string]interface{} "未开放注册，不能创建账号"
+// @Failure 404 {object} map[string]interface{} "未配置该登录方式"
+// @Failure 409 {object} map[string]interface{} "邮箱已注册或身份已关联其他用户"
+// @Failure 422 {object} map[string]interface{} "身份提供方没有返回邮箱"
+// @Failure 502 {object} map[string]interface{} "身份提供方不可用"
+// @Router /api/users/oidc/{provider}/callback [post]
+func (c *OIDCController) Callback(ctx *gin.Context) {
+	var request models.OIDCCallbackRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	result, err := c.OIDCService.Callback(ctx.Request.Context(), ctx.Param("provider"), request.Code, request.State)
+	if err != nil {
+		respondOIDCError(ctx, err)
+		return
+	}
+	if result.Linked {
+		c.respondIdentities(ctx, result.User)
+		return
+	}
+	// 与密码登录一致，开启了两步验证时还需要验证码
+	if result.User.TwoFactorEnabled() {
+		challengeToken, err := c.TwoFactorService.Challenge(result.User)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+			return
+		}
+		ctx.JSON(http.StatusOK, models.TwoFactorChallengeResponse{TwoFactorRequired: true, ChallengeToken: challengeToken})
+		return
+	}
+	token, refreshToken, err := c.TokenService.IssueTokens(result.User, requestClient(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	ctx.JSON(http.StatusOK, newUserResponse(result.User, token, refreshToken))
+}
+
+// ListIdentities godoc
+// @Summary 已关联的第三方账号
+// @Tags oidc
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.IdentitiesResponse
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/identities [get]
+func (c *OIDCController) ListIdentities(ctx *gin.Context) {
+	c.respondIdentities(ctx, utils.CurrentUser(ctx))
+}
+
+// UnlinkIdentity godoc
+// @Summary 解除关联第三方账号
+// @Description 没有设置密码的账号不能解除最后一个关联
+// @Tags oidc
+// @Produce  json
+// @Security BearerAuth
+// @Param   provider path string true "身份提供方名称"
+// @Success 204
+// @Failure 401 {object} map[string]interface{} "未授权"
+// @Failure 404 {object} map[string]interface{} "未关联该第三方账号"
+// @Failure 409 {object} map[string]interface{} "账号唯一的登录方式"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /api/user/identities/{provider} [delete]
+func (c *OIDCController) UnlinkIdentity(ctx *gin.Context) {
+	if err := c.OIDCService.Unlink(utils.CurrentUser(ctx), ctx.Param("provider")); err != nil {
+		respondOIDCError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *OIDCController) respondIdentities(ctx *gin.Context, user *models.UserModel) {
+	identities, err := c.OIDCService.ListIdentities(user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	response := models.IdentitiesResponse{Identities: []models.IdentityView{}}
+	for i := range identities {
+		response.Identities = append(response.Identities, identities[i].View())
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// respondOIDCError 把第三方登录的错误映射为状态码
+func respondOIDCError(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrUnknownProvider), errors.Is(err, service.ErrIdentityNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidOIDCState):
+		status = http.StatusBadRequest
+	case errors.Is(err, oidc.ErrExchangeFailed), errors.Is(err, oidc.ErrInvalidIDToken):
+		status = http.StatusUnauthorized
+	case errors.Is(err, service.ErrIdentityLinked), errors.Is(err, service.ErrOIDCEmailTaken),
+		errors.Is(err, service.ErrProviderAlreadySet), errors.Is(err, service.ErrLastSignInMethod):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrOIDCEmailMissing):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrOIDCSignupDisabled):
+		status = http.StatusForbidden
+	case errors.Is(err, oidc.ErrProviderUnavailable):
+		status = http.StatusBadGateway
+	}
+	ctx.JSON(status, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+}
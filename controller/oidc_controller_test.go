@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"goDemo/config"
+	"goDemo/migrations"
+	"goDemo/models"
+	"goDemo/oidc"
+	"goDemo/service"
+	"goDemo/test/mockoidc/idp"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDB 为每个测试创建独立的 SQLite 内存数据库，通过与生产相同的方言和迁移建表
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", url.PathEscape(t.Name()))
+	db, err := config.InitDB(config.DatabaseConfig{Driver: "sqlite", DSN: config.Secret(dsn)})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败：%v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("执行迁移失败：%v", err)
+	}
+	return db
+}
+
+func TestOIDCCallbackTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name      string
+		twoFactor bool
+	}{
+		{"two factor enabled", true},
+		{"two factor disabled", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			mock, err := idp.New("", "realworld", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			server := httptest.NewServer(mock)
+			defer server.Close()
+			mock.Issuer = server.URL
+			provider := oidc.New(config.OIDCProviderConfig{Name: "mock", Issuer: server.URL, ClientID: "realworld"}, "http://realworld.test")
+
+			keys, err := utils.NewKeyring(0, utils.NewHMACKey("test", []byte("test-secret"), time.Time{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			auth := utils.NewAuth(keys, time.Hour)
+			oidcService := &service.OIDCService{
+				DB:          db,
+				Providers:   map[string]*oidc.Provider{provider.Name: provider},
+				StateTTL:    10 * time.Minute,
+				Invitations: &service.InvitationService{DB: db, Mode: service.RegistrationOpen},
+			}
+			twoFactorService := &service.TwoFactorService{DB: db, Auth: auth, ChallengeTTL: 5 * time.Minute}
+			c := &OIDCController{
+				OIDCService:      oidcService,
+				TokenService:     &service.TokenService{DB: db, Auth: auth, RefreshTTL: time.Hour},
+				TwoFactorService: twoFactorService,
+			}
+			router := gin.New()
+			router.POST("/api/users/oidc/:provider/callback", c.Callback)
+
+			// 已关联该身份的用户，按用例开启两步验证
+			user := &models.UserModel{Username: "alice", Email: "alice@example.com", Role: "user"}
+			if tt.twoFactor {
+				now := time.Now()
+				user.TOTPSecret = "JBSWY3DPEHPK3PXP"
+				user.TOTPEnabledAt = &now
+			}
+			if err := db.Create(user).Error; err != nil {
+				t.Fatal(err)
+			}
+			err = db.Create(&models.UserIdentity{UserID: user.ID, Provider: "mock", Subject: "sub-1"}).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			authURL, err := oidcService.Authorize(context.Background(), "mock", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, state, err := mock.Login(authURL, url.Values{"sub": {"sub-1"}, "email": {user.Email}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := fmt.Sprintf(`{"code":%q,"state":%q}`, code, state)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/users/oidc/mock/callback", strings.NewReader(body)))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", recorder.Code, recorder.Body.String())
+			}
+
+			var sessions int64
+			if err := db.Model(&models.Session{}).Count(&sessions).Error; err != nil {
+				t.Fatal(err)
+			}
+			if !tt.twoFactor {
+				var response models.UserResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+					t.Fatal(err)
+				}
+				if response.User.Token == "" || response.User.RefreshToken == "" || sessions != 1 {
+					t.Errorf("未开启两步验证时应直接签发令牌：%s，sessions = %d", recorder.Body.String(), sessions)
+				}
+				return
+			}
+
+			// 开启两步验证时只返回挑战令牌，不签发访问令牌，也不创建会话
+			var challenge models.TwoFactorChallengeResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &challenge); err != nil {
+				t.Fatal(err)
+			}
+			if !challenge.TwoFactorRequired || challenge.ChallengeToken == "" {
+				t.Fatalf("response = %s, want two factor challenge", recorder.Body.String())
+			}
+			if strings.Contains(recorder.Body.String(), `"token"`) || sessions != 0 {
+				t.Errorf("开启两步验证时不应签发令牌：%s，sessions = %d", recorder.Body.String(), sessions)
+			}
+			challenged, err := twoFactorService.ParseChallenge(challenge.ChallengeToken)
+			if err != nil {
+				t.Fatalf("ParseChallenge() error = %v", err)
+			}
+			if challenged.ID != user.ID {
+				t.Errorf("challenge user = %d, want %d", challenged.ID, user.ID)
+			}
+		})
+	}
+}
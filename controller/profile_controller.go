@@ -12,7 +12,6 @@ import (
 type ProfileController struct {
 	ProfileService *service.ProfileService
 	UserService    *service.UserService
-	Auth           *utils.Auth
 }
 
 // GetProfile godoc
@@ -30,17 +29,8 @@ type ProfileController struct {
 // @Router /api/profiles/{username} [get]
 func (c *ProfileController) GetProfile(ctx *gin.Context) {
 	username := ctx.Param("username")
-	currentUserID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		if err.Error() == "missing Authorization header" {
-			// 没有 token 也可以继续获取资料，只是 currentUserID 为 0
-			currentUserID = 0
-			err = nil
-		} else {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
-			return
-		}
-	}
+	// 没有 token 也可以继续获取资料，只是 currentUserID 为 0
+	currentUserID := utils.CurrentUserID(ctx)
 	// 调用服务层获取用户资料
 	profile, err := c.ProfileService.GetProfile(currentUserID, username)
 	if err != nil {
@@ -70,15 +60,7 @@ func (c *ProfileController) GetProfile(ctx *gin.Context) {
 func (c *ProfileController) FollowUser(ctx *gin.Context) {
 	// 从路径参数获取要关注的用户名
 	username := ctx.Param("username")
-	currentUserID, err := c.Auth.ParseToken(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
-		return
-	}
-	if currentUserID == 0 {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权，缺少或无效的 token"}}})
-		return
-	}
+	currentUserID := utils.CurrentUserID(ctx)
 	// 调用服务层关注用户
 	profile, err := c.ProfileService.FollowUser(currentUserID, username)
 	if err != nil {
@@ -110,16 +92,7 @@ func (c *ProfileController) FollowUser(ctx *gin.Context) {
 // @Router /api/profiles/{username}/follow [delete]
 func (c *ProfileController) UnfollowUser(ctx *gin.Context) {
 	username := ctx.Param("username")
-	currentUserID, err := c.Auth.ParseToken(ctx)
-	//检查token
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
-		return
-	}
-	if currentUserID == 0 {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权，缺少或无效的 token"}}})
-		return
-	}
+	currentUserID := utils.CurrentUserID(ctx)
 	//调用服务层取消关注用户
 	profile, err := c.ProfileService.UnfollowUser(currentUserID, username)
 	if err != nil {
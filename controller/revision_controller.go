@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"goDemo/models"
+	"goDemo/policy"
+	"goDemo/service"
+	"goDemo/utils"
+	"net/http"
+	"strconv"
+)
+
+type RevisionController struct {
+	RevisionService *service.RevisionService
+}
+
+// ListRevisions godoc
+// @Summary 文章历史版本
+// @Description 列出文章的所有版本，最新的在前。创建文章和每次修改标题、描述或正文都会产生一个版本，只有可以编辑文章的用户可以查看
+// @Tags revisions
+// @Produce  json
+// @Security BearerAuth
+// @Param   slug path string true "文章slug"
+// @Success 200 {object} models.RevisionsResponse
+// @Failure 403 {object} map[string]interface{} "无权查看历史版本"
+// @Failure 404 {object} map[string]interface{} "文章没找到"
+// @Router /api/articles/{slug}/revisions [get]
+func (c *RevisionController) ListRevisions(ctx *gin.Context) {
+	revisions, err := c.RevisionService.List(utils.CurrentUser(ctx), ctx.Param("slug"))
+	if err != nil {
+		respondRevisionError(ctx, err)
+		return
+	}
+	response := models.RevisionsResponse{Revisions: []models.RevisionView{}, RevisionsCount: len(revisions)}
+	for i := range revisions {
+		response.Revisions = append(response.Revisions, revisions[i].View())
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetRevision godoc
+// @Summary 获取文章的某个版本
+// @Tags revisions
+// @Produce  json
+// @Security BearerAuth
+// @Param   slug path string true "文章slug"
+// @Param   number path int true "版本号"
+// @Success 200 {object} models.RevisionResponse
+// @Failure 403 {object} map[string]interface{} "无权查看历史版本"
+// @Failure 404 {object} map[string]interface{} "文章或版本不存在"
+// @Router /api/articles/{slug}/revisions/{number} [get]
+func (c *RevisionController) GetRevision(ctx *gin.Context) {
+	number, ok := revisionNumber(ctx, ctx.Param("number"))
+	if !ok {
+		return
+	}
+	revision, err := c.RevisionService.Get(utils.CurrentUser(ctx), ctx.Param("slug"), number)
+	if err != nil {
+		respondRevisionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.RevisionResponse{Revision: revision.View()})
+}
+
+// DiffRevisions godoc
+// @Summary 比较两个版本
+// @Description 逐行比较标题、描述、正文和标签。不指定 to 时与最新版本比较，不指定 from 时与 to 的上一个版本比较
+// @Tags revisions
+// @Produce  json
+// @Security BearerAuth
+// @Param   slug path string true "文章slug"
+// @Param   from query int false "旧版本号"
+// @Param   to query int false "新版本号"
+// @Success 200 {object} models.RevisionDiffResponse
+// @Failure 400 {object} map[string]interface{} "无效的版本号"
+// @Failure 403 {object} map[string]interface{} "无权查看历史版本"
+// @Failure 404 {object} map[string]interface{} "文章或版本不存在"
+// @Router /api/articles/{slug}/diff [get]
+func (c *RevisionController) DiffRevisions(ctx *gin.Context) {
+	var from, to int
+	if value := ctx.Query("from"); value != "" {
+		var ok bool
+		if from, ok = revisionNumber(ctx, value); !ok {
+			return
+		}
+	}
+	if value := ctx.Query("to"); value != "" {
+		var ok bool
+		if to, ok = revisionNumber(ctx, value); !ok {
+			return
+		}
+	}
+	diff, err := c.RevisionService.Diff(utils.CurrentUser(ctx), ctx.Param("slug"), from, to)
+	if err != nil {
+		respondRevisionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.RevisionDiffResponse{Diff: *diff})
+}
+
+// RestoreRevision godoc
+// @Summary 恢复到某个版本
+// @Description 用该版本的标题、描述、正文和标签覆盖文章，并记录为一个新版本，不改变文章状态
+// @Tags revisions
+// @Produce  json
+// @Security BearerAuth
+// @Param   slug path string true "文章slug"
+// @Param   number path int true "版本号"
+// @Success 200 {object} models.ArticleResponse
+// @Failure 403 {object} map[string]interface{} "无权修改该文章"
+// @Failure 404 {object} map[string]interface{} "文章或版本不存在"
+// @Router /api/articles/{slug}/revisions/{number}/restore [post]
+func (c *RevisionController) RestoreRevision(ctx *gin.Context) {
+	number, ok := revisionNumber(ctx, ctx.Param("number"))
+	if !ok {
+		return
+	}
+	article, err := c.RevisionService.Restore(utils.CurrentUser(ctx), ctx.Param("slug"), number)
+	if err != nil {
+		respondRevisionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.ArticleResponse{Article: *article})
+}
+
+// revisionNumber 解析版本号，无效时直接返回 400
+func revisionNumber(ctx *gin.Context, value string) (int, bool) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"无效的版本号"}}})
+		return 0, false
+	}
+	return number, true
+}
+
+func respondRevisionError(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrArticleNotFound), errors.Is(err, service.ErrRevisionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, policy.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidTag):
+		status = http.StatusUnprocessableEntity
+	}
+	ctx.JSON(status, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+}
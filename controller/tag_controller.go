@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"goDemo/models"
+	"goDemo/service"
+	"net/http"
+)
+
+// maxTagsLimit 一次最多返回的标签数量
+const maxTagsLimit = 100
+
+type TagController struct {
+	TagService *service.TagService
+}
+
+// ListTags godoc
+// @Summary 热门标签
+// @Description 按使用标签的已发布文章数量从多到少列出标签及数量
+// @Tags tags
+// @Produce  json
+// @Param   limit query int false "返回数量，默认 20，最多 100"
+// @Success 200 {object} models.TagsResponse
+// @Router /api/tags [get]
+func (c *TagController) ListTags(ctx *gin.Context) {
+	limit := getIntQuery(ctx, "limit", 20)
+	if limit <= 0 {
+		limit = 20
+	} else if limit > maxTagsLimit {
+		limit = maxTagsLimit
+	}
+	counts, err := c.TagService.Popular(limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	response := models.TagsResponse{Tags: []string{}, TagCounts: []models.TagCount{}}
+	for _, count := range counts {
+		response.Tags = append(response.Tags, count.Tag)
+		response.TagCounts = append(response.TagCounts, count)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
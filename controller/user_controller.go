@@ -1,28 +1,39 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"goDemo/lockout"
 	"goDemo/models"
+	"goDemo/passwords"
 	"goDemo/service"
 	"goDemo/utils"
+	"math"
 	"net/http"
-	"strings"
+	"strconv"
 )
 
 type UserController struct {
-	UserService *service.UserService
-	Auth        *utils.Auth
+	UserService          *service.UserService
+	TokenService         *service.TokenService
+	PasswordResetService *service.PasswordResetService
+	VerificationService  *service.VerificationService
+	TwoFactorService     *service.TwoFactorService
+	Lockout              *lockout.Limiter
 }
 
 // RegisterUser godoc
 // @Summary 注册用户
-// @Description 接收用户信息，将用户信息存储到数据库中完成注册
+// @Description 接收用户信息，将用户信息存储到数据库中完成注册；仅限邀请注册时必须填写邀请码
 // @Tags users
 // @Accept  json
 // @Produce  json
-// @Param   user body models.UserModel true "用户注册信息"
+// @Param   user body models.RegisterRequest true "用户注册信息"
 // @Success 201 {object} models.UserModel "注册成功，返回创建的用户信息"
 // @Failure 400 {object} map[string]string "请求参数错误"
+// @Failure 403 {object} map[string]interface{} "暂不开放注册"
+// @Failure 422 {object} models.PasswordPolicyErrorResponse "密码不符合策略，或邀请码缺失、无效"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /api/users/register [post]
 func (c *UserController) RegisterUser(ctx *gin.Context) {
@@ -32,25 +43,43 @@ func (c *UserController) RegisterUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	user := registerRequest.User
-	if err := c.UserService.CreateUser(&user); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	user := models.UserModel{
+		Username: registerRequest.User.Username,
+		Email:    registerRequest.User.Email,
+		Password: registerRequest.User.Password,
+		Bio:      registerRequest.User.Bio,
+		Image:    registerRequest.User.Image,
+	}
+	if err := c.UserService.CreateUser(&user, registerRequest.User.InvitationCode); err != nil {
+		switch {
+		case errors.Is(err, service.ErrRegistrationClosed):
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		case errors.Is(err, service.ErrInvitationRequired), errors.Is(err, service.ErrInvalidInvitation):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"invitationCode": []string{err.Error()}}})
+		case !respondPasswordError(ctx, err):
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
+	// 验证邮件发送失败不影响注册，用户可以稍后重新发送
+	if err := c.VerificationService.Send(&user); err != nil {
+		_ = ctx.Error(err)
+	}
 
 	ctx.JSON(http.StatusCreated, user)
 }
 
 // LoginUser godoc
 // @Summary 用户登录
-// @Description 接收用户登录信息，验证用户信息并返回token
+// @Description 接收用户登录信息，验证用户信息并返回token；开启了两步验证时返回挑战令牌
 // @Tags users
 // @Accept  json
 // @Produce  json
-// @Param   user body models.UserModel true "用户登录信息"
+// @Param   user body models.UserLoginRequest true "用户登录信息"
 // @Success 200 {object} map[string]string "登录成功，返回token"
 // @Failure 400 {object} map[string]string "请求参数错误"
 // @Failure 401 {object} map[string]string "用户名或密码错误"
+// @Failure 429 {object} map[string]string "失败次数过多，稍后再试"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /api/users/login [post]
 func (c *UserController) LoginUser(ctx *gin.Context) {
@@ -60,42 +89,48 @@ func (c *UserController) LoginUser(ctx *gin.Context) {
 		return
 	}
 
+	// 失败次数过多时直接拒绝，不再校验密码
+	if err := c.Lockout.Check(loginRequest.User.Email, ctx.ClientIP()); err != nil {
+		respondLockoutError(ctx, err)
+		return
+	}
 	user, err := c.UserService.VerifyUser(loginRequest.User.Email, loginRequest.User.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		return
 	}
 	if user == nil {
+		if err := c.Lockout.Fail(loginRequest.User.Email, ctx.ClientIP()); err != nil {
+			_ = ctx.Error(err)
+		}
 		ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"用户名或密码错误"}}})
 		return
 	}
+	// 开启了两步验证时先返回挑战令牌，凭验证码调用 /users/login/2fa 换取访问令牌
+	if user.TwoFactorEnabled() {
+		challengeToken, err := c.TwoFactorService.Challenge(user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+			return
+		}
+		ctx.JSON(http.StatusOK, models.TwoFactorChallengeResponse{TwoFactorRequired: true, ChallengeToken: challengeToken})
+		return
+	}
+	if err := c.Lockout.Succeed(user.Email); err != nil {
+		_ = ctx.Error(err)
+	}
 
-	token, err := c.Auth.GenerateToken(user)
+	token, refreshToken, err := c.TokenService.IssueTokens(user, requestClient(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		return
 	}
-	response := models.UserResponse{
-		User: struct {
-			Email    string `json:"email"`
-			Token    string `json:"token"`
-			Username string `json:"username"`
-			Bio      string `json:"bio"`
-			Image    string `json:"image"`
-		}{
-			Email:    user.Email,
-			Token:    token,
-			Username: user.Username,
-			Bio:      user.Bio,
-			Image:    user.Image,
-		},
-	}
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, newUserResponse(user, token, refreshToken))
 }
 
 // GetCurrentUser godoc
 // @Summary 获取当前用户信息
-// @Description 根据token获取当前用户信息
+// @Description 根据token获取当前用户信息，管理员代入时返回 impersonation 字段
 // @Tags users
 // @Accept  json
 // @Produce  json
@@ -105,38 +140,16 @@ func (c *UserController) LoginUser(ctx *gin.Context) {
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /api/user [get]
 func (c *UserController) GetCurrentUser(ctx *gin.Context) {
-	user, err := c.UserService.GetUserByToken(ctx)
-	if err != nil {
-		if err.Error() == "missing Authorization header" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"缺少 Authorization 请求头"}}})
-		} else if err.Error() == "invalid Authorization header format" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"无效的 Authorization 请求头形式"}}})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{"无效的Token"}}})
+	principal, _ := utils.CurrentPrincipal(ctx)
+	user := principal.User
+	// 返回当前请求携带的 token，不再每次签发新的
+	token, _ := utils.BearerToken(ctx)
+	response := newUserResponse(user, token, "")
+	if principal.Actor != nil {
+		response.User.Impersonation = &models.ImpersonationFlag{
+			ImpersonatedBy: principal.Actor.Username,
+			ExpiresAt:      principal.Claims.ExpiresAt,
 		}
-		return
-	}
-	// 生成新的 token
-	token, err := c.Auth.GenerateToken(user)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
-		return
-	}
-	// 构建响应
-	response := models.UserResponse{
-		User: struct {
-			Email    string `json:"email"`
-			Token    string `json:"token"`
-			Username string `json:"username"`
-			Bio      string `json:"bio"`
-			Image    string `json:"image"`
-		}{
-			Email:    user.Email,
-			Token:    token,
-			Username: user.Username,
-			Bio:      user.Bio,
-			Image:    user.Image,
-		},
 	}
 	ctx.JSON(http.StatusOK, response)
 }
@@ -152,6 +165,7 @@ func (c *UserController) GetCurrentUser(ctx *gin.Context) {
 // @Success 200 {object} models.UserModel "更新成功，返回更新后的用户信息"
 // @Failure 400 {object} map[string]string "请求参数错误"
 // @Failure 401 {object} map[string]string "未授权"
+// @Failure 422 {object} models.PasswordPolicyErrorResponse "新密码不符合策略"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /api/user [put]
 func (c *UserController) UpdateUser(ctx *gin.Context) {
@@ -162,38 +176,527 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 		return
 	}
 	// 调用服务层更新用户信息
-	updatedUser, err := c.UserService.UpdateUser(ctx, &updateRequest)
+	principal, _ := utils.CurrentPrincipal(ctx)
+	previousEmail := principal.User.Email
+	updatedUser, err := c.UserService.UpdateUser(principal.User, &updateRequest)
+	if err != nil {
+		if !respondPasswordError(ctx, err) {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	// 更换邮箱后向新邮箱发送验证链接
+	if updatedUser.Email != previousEmail {
+		if err := c.VerificationService.Send(updatedUser); err != nil {
+			_ = ctx.Error(err)
+		}
+	}
+	// 修改密码后吊销所有已签发的刷新令牌，并为当前客户端签发新的令牌
+	if updateRequest.User.Password != nil {
+		if err := c.TokenService.RevokeAllForUser(updatedUser.ID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+			return
+		}
+		token, refreshToken, err := c.TokenService.IssueTokens(updatedUser, requestClient(ctx))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+			return
+		}
+		ctx.JSON(http.StatusOK, newUserResponse(updatedUser, token, refreshToken))
+		return
+	}
+	token, _ := utils.BearerToken(ctx)
+	ctx.JSON(http.StatusOK, newUserResponse(updatedUser, token, ""))
+}
+
+// RefreshToken godoc
+// @Summary 刷新令牌
+// @Description 使用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随即失效
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param   body body models.RefreshTokenRequest true "刷新令牌"
+// @Success 200 {object} models.UserResponse "刷新成功，返回新的令牌"
+// @Failure 400 {object} map[string]string "请求参数错误"
+// @Failure 401 {object} map[string]string "刷新令牌无效、过期或已被使用"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/users/refresh [post]
+func (c *UserController) RefreshToken(ctx *gin.Context) {
+	var request models.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	user, token, refreshToken, err := c.TokenService.Refresh(request.RefreshToken, requestClient(ctx))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, newUserResponse(user, token, refreshToken))
+}
+
+// Logout godoc
+// @Summary 退出登录
+// @Description 吊销当前访问令牌以及本次登录签发的全部刷新令牌
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Success 204 "退出成功"
+// @Failure 401 {object} map[string]string "未授权"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/users/logout [post]
+func (c *UserController) Logout(ctx *gin.Context) {
+	principal, _ := utils.CurrentPrincipal(ctx)
+	if err := c.TokenService.Logout(principal.Claims); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListSessions godoc
+// @Summary 登录会话列表
+// @Description 列出当前用户所有有效的登录会话，包括设备、IP 和最近活动时间，current 标记发起本次请求的会话
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.SessionsResponse
+// @Failure 401 {object} map[string]string "未授权"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/user/sessions [get]
+func (c *UserController) ListSessions(ctx *gin.Context) {
+	principal, _ := utils.CurrentPrincipal(ctx)
+	sessions, err := c.TokenService.ListSessions(principal.User.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	response := models.SessionsResponse{Sessions: []models.SessionView{}}
+	for i := range sessions {
+		response.Sessions = append(response.Sessions, sessions[i].View(principal.Claims.FamilyID))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RevokeSession godoc
+// @Summary 退出指定会话
+// @Description 结束指定的登录会话，该会话的访问令牌和刷新令牌立即失效
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   id path int true "会话 ID"
+// @Success 204 "退出成功"
+// @Failure 400 {object} map[string]string "无效的会话 ID"
+// @Failure 401 {object} map[string]string "未授权"
+// @Failure 404 {object} map[string]string "会话不存在"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/user/sessions/{id} [delete]
+func (c *UserController) RevokeSession(ctx *gin.Context) {
+	var sessionID uint
+	if _, err := fmt.Sscanf(ctx.Param("id"), "%d", &sessionID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"无效的会话 ID"}}})
+		return
+	}
+	if err := c.TokenService.RevokeSession(utils.CurrentUserID(ctx), sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RevokeOtherSessions godoc
+// @Summary 退出其他会话
+// @Description 结束除当前会话以外的全部登录会话
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Success 204 "退出成功"
+// @Failure 401 {object} map[string]string "未授权"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/user/sessions [delete]
+func (c *UserController) RevokeOtherSessions(ctx *gin.Context) {
+	principal, _ := utils.CurrentPrincipal(ctx)
+	if err := c.TokenService.RevokeOtherSessions(principal.User.ID, principal.Claims.FamilyID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// requestClient 记录在会话中的客户端信息
+func requestClient(ctx *gin.Context) service.Client {
+	return service.Client{UserAgent: ctx.Request.UserAgent(), IP: ctx.ClientIP()}
+}
+
+// RequestPasswordReset godoc
+// @Summary 申请重置密码
+// @Description 向邮箱发送一次性的密码重置链接，邮箱未注册时同样返回 202
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param   request body models.PasswordResetRequest true "注册邮箱"
+// @Success 202 "已受理"
+// @Failure 400 {object} map[string]string "请求参数错误"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/users/password-reset [post]
+func (c *UserController) RequestPasswordReset(ctx *gin.Context) {
+	var request models.PasswordResetRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	if err := c.PasswordResetService.RequestReset(request.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
+
+// ConfirmPasswordReset godoc
+// @Summary 确认重置密码
+// @Description 使用邮件中的重置令牌设置新密码，成功后该用户需要重新登录
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param   request body models.PasswordResetConfirmRequest true "重置令牌和新密码"
+// @Success 204 "重置成功"
+// @Failure 400 {object} map[string]string "令牌无效或已过期"
+// @Failure 422 {object} models.PasswordPolicyErrorResponse "新密码不符合策略"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/users/password-reset/confirm [post]
+func (c *UserController) ConfirmPasswordReset(ctx *gin.Context) {
+	var request models.PasswordResetConfirmRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	if err := c.PasswordResetService.ConfirmReset(request.Token, request.Password); err != nil {
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else if respondPasswordError(ctx, err) {
+			return
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// VerifyEmail godoc
+// @Summary 验证邮箱
+// @Description 使用验证邮件中的令牌完成邮箱验证
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param   request body models.VerifyEmailRequest true "验证令牌"
+// @Success 204 "验证成功"
+// @Failure 400 {object} map[string]string "令牌无效或已过期"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/users/verify-email [post]
+func (c *UserController) VerifyEmail(ctx *gin.Context) {
+	var request models.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	if _, err := c.VerificationService.Verify(request.Token); err != nil {
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ResendVerification godoc
+// @Summary 重新发送验证邮件
+// @Description 向当前用户的邮箱重新发送验证链接，发送频率受限
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Success 202 "已发送"
+// @Failure 401 {object} map[string]string "未授权"
+// @Failure 409 {object} map[string]string "邮箱已验证"
+// @Failure 429 {object} map[string]string "发送过于频繁"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/users/verify-email/resend [post]
+func (c *UserController) ResendVerification(ctx *gin.Context) {
+	err := c.VerificationService.Resend(utils.CurrentUser(ctx))
+	if err != nil {
+		var tooSoon *service.ResendTooSoonError
+		if errors.As(err, &tooSoon) {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(tooSoon.RetryAfter.Seconds()))))
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else if errors.Is(err, service.ErrEmailAlreadyVerified) {
+			ctx.JSON(http.StatusConflict, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
+
+// LoginTwoFactor godoc
+// @Summary 两步验证登录
+// @Description 使用登录接口返回的挑战令牌和认证器验证码（或恢复码）换取访问令牌
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param   request body models.TwoFactorLoginRequest true "挑战令牌和验证码"
+// @Success 200 {object} models.UserResponse "登录成功"
+// @Failure 400 {object} map[string]string "请求参数错误"
+// @Failure 401 {object} map[string]string "挑战令牌无效或验证码错误"
+// @Failure 429 {object} map[string]string "失败次数过多，稍后再试"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/users/login/2fa [post]
+func (c *UserController) LoginTwoFactor(ctx *gin.Context) {
+	var request models.TwoFactorLoginRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	user, err := c.TwoFactorService.ParseChallenge(request.ChallengeToken)
 	if err != nil {
-		if err.Error() == "missing Authorization header" || err.Error() == "invalid Authorization header format" || strings.Contains(err.Error(), "invalid token") || strings.Contains(err.Error(), "user not found") {
+		if errors.Is(err, service.ErrInvalidChallengeToken) {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		}
 		return
 	}
-	// 生成新的 token
-	newToken, err := c.Auth.GenerateToken(updatedUser)
+	// 验证码与密码共用同一套失败计数，防止在挑战令牌有效期内穷举验证码
+	if err := c.Lockout.Check(user.Email, ctx.ClientIP()); err != nil {
+		respondLockoutError(ctx, err)
+		return
+	}
+	if err := c.TwoFactorService.CompleteLogin(user, request.Code); err != nil {
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			if err := c.Lockout.Fail(user.Email, ctx.ClientIP()); err != nil {
+				_ = ctx.Error(err)
+			}
+			ctx.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		}
+		return
+	}
+	if err := c.Lockout.Succeed(user.Email); err != nil {
+		_ = ctx.Error(err)
+	}
+	token, refreshToken, err := c.TokenService.IssueTokens(user, requestClient(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
 		return
 	}
-	// 构建响应
-	response := models.UserResponse{
-		User: struct {
-			Email    string `json:"email"`
-			Token    string `json:"token"`
-			Username string `json:"username"`
-			Bio      string `json:"bio"`
-			Image    string `json:"image"`
-		}{
-			Email:    updatedUser.Email,
-			Token:    newToken,
-			Username: updatedUser.Username,
-			Bio:      updatedUser.Bio,
-			Image:    updatedUser.Image,
-		},
-	}
-
-	// 返回响应
+	ctx.JSON(http.StatusOK, newUserResponse(user, token, refreshToken))
+}
+
+// EnrollTwoFactor godoc
+// @Summary 绑定认证器
+// @Description 生成 TOTP 密钥和 otpauth 地址，需要调用确认接口后才会开启两步验证
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.TwoFactorEnrollResponse
+// @Failure 401 {object} map[string]string "未授权"
+// @Failure 409 {object} map[string]string "已开启两步验证"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/user/2fa/enroll [post]
+func (c *UserController) EnrollTwoFactor(ctx *gin.Context) {
+	response, err := c.TwoFactorService.Enroll(utils.CurrentUser(ctx))
+	if err != nil {
+		respondTwoFactorError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, response)
 }
+
+// ConfirmTwoFactor godoc
+// @Summary 确认绑定认证器
+// @Description 提交认证器上的验证码，成功后开启两步验证并返回恢复码，恢复码只显示这一次
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   request body models.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400 {object} map[string]string "验证码错误"
+// @Failure 401 {object} map[string]string "未授权"
+// @Failure 409 {object} map[string]string "已开启两步验证或尚未绑定"
+// @Failure 429 {object} map[string]string "失败次数过多，稍后再试"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/user/2fa/confirm [post]
+func (c *UserController) ConfirmTwoFactor(ctx *gin.Context) {
+	var request models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	user := utils.CurrentUser(ctx)
+	var codes []string
+	ok := c.limitTwoFactorCode(ctx, user, func() (err error) {
+		codes, err = c.TwoFactorService.Confirm(user, request.Code)
+		return err
+	})
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor godoc
+// @Summary 关闭两步验证
+// @Description 提交验证码或恢复码后关闭两步验证
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   request body models.TwoFactorCodeRequest true "验证码或恢复码"
+// @Success 204 "已关闭"
+// @Failure 400 {object} map[string]string "验证码错误"
+// @Failure 401 {object} map[string]string "未授权"
+// @Failure 409 {object} map[string]string "未开启两步验证"
+// @Failure 429 {object} map[string]string "失败次数过多，稍后再试"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/user/2fa/disable [post]
+func (c *UserController) DisableTwoFactor(ctx *gin.Context) {
+	var request models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	user := utils.CurrentUser(ctx)
+	ok := c.limitTwoFactorCode(ctx, user, func() error {
+		return c.TwoFactorService.Disable(user, request.Code)
+	})
+	if !ok {
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary 重新生成恢复码
+// @Description 提交验证码后重新生成恢复码，旧的恢复码全部作废
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   request body models.TwoFactorCodeRequest true "验证码或恢复码"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400 {object} map[string]string "验证码错误"
+// @Failure 401 {object} map[string]string "未授权"
+// @Failure 409 {object} map[string]string "未开启两步验证"
+// @Failure 429 {object} map[string]string "失败次数过多，稍后再试"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /api/user/2fa/recovery-codes [post]
+func (c *UserController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var request models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	user := utils.CurrentUser(ctx)
+	var codes []string
+	ok := c.limitTwoFactorCode(ctx, user, func() (err error) {
+		codes, err = c.TwoFactorService.RegenerateRecoveryCodes(user, request.Code)
+		return err
+	})
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// limitTwoFactorCode 两步验证管理接口的验证码与登录共用同一套失败计数，防止盗用会话后穷举验证码关闭两步验证；
+// 返回 false 时已写入错误响应
+func (c *UserController) limitTwoFactorCode(ctx *gin.Context, user *models.UserModel, verify func() error) bool {
+	if err := c.Lockout.Check(user.Email, ctx.ClientIP()); err != nil {
+		respondLockoutError(ctx, err)
+		return false
+	}
+	if err := verify(); err != nil {
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			if err := c.Lockout.Fail(user.Email, ctx.ClientIP()); err != nil {
+				_ = ctx.Error(err)
+			}
+		}
+		respondTwoFactorError(ctx, err)
+		return false
+	}
+	if err := c.Lockout.Succeed(user.Email); err != nil {
+		_ = ctx.Error(err)
+	}
+	return true
+}
+
+// respondPasswordError 新密码不符合策略时返回 422，列出每一项原因，已处理时返回 true
+func respondPasswordError(ctx *gin.Context, err error) bool {
+	var invalid *passwords.ValidationError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+	ctx.JSON(http.StatusUnprocessableEntity, models.PasswordPolicyErrorResponse{
+		Errors:     map[string][]string{"password": invalid.Messages()},
+		Violations: invalid.Violations,
+	})
+	return true
+}
+
+// respondLockoutError 登录被限制时返回 429 和 Retry-After
+func respondLockoutError(ctx *gin.Context, err error) {
+	var locked *lockout.LockedError
+	if errors.As(err, &locked) {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+}
+
+// respondTwoFactorError 把两步验证管理接口的错误转换为响应
+func respondTwoFactorError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnrolled),
+		errors.Is(err, service.ErrTwoFactorNotEnabled):
+		ctx.JSON(http.StatusConflict, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+	}
+}
+
+// newUserResponse 构建用户信息响应，refreshToken 为空时不返回该字段
+func newUserResponse(user *models.UserModel, token string, refreshToken string) models.UserResponse {
+	var response models.UserResponse
+	response.User.Email = user.Email
+	response.User.Token = token
+	response.User.RefreshToken = refreshToken
+	response.User.Username = user.Username
+	response.User.Bio = user.Bio
+	response.User.Image = user.Image
+	response.User.Role = user.Role
+	response.User.EmailVerified = user.EmailVerified()
+	response.User.DeletionScheduledAt = user.DeletionScheduledAt
+	return response
+}
@@ -0,0 +1,52 @@
+package dialect
+
+import (
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Dialect 封装不同数据库之间不兼容的 SQL 写法
+// 业务代码只依赖这个接口，需要新的厂商特定语法时在这里扩展
+type Dialect interface {
+	// Name 方言名称，与配置项 database.driver 以及 gorm Dialector.Name() 一致
+	Name() string
+	// Open 根据连接串创建 gorm Dialector
+	Open(dsn string) gorm.Dialector
+	// SkipLocked 生成"锁定选中的行并跳过已被其他事务锁定的行"的查询子句，用于多个实例分头领取任务；
+	// 不支持行锁的数据库返回空
+	SkipLocked() []clause.Expression
+}
+
+var dialects = map[string]Dialect{}
+
+// register 注册方言，由各实现文件在 init 中调用
+func register(d Dialect) {
+	dialects[d.Name()] = d
+}
+
+// Lookup 根据名称获取方言
+func Lookup(name string) (Dialect, error) {
+	d, ok := dialects[name]
+	if !ok {
+		return nil, fmt.Errorf("不支持的数据库类型：%s，可选值：%v", name, Names())
+	}
+	return d, nil
+}
+
+// Names 返回所有已注册的方言名称
+func Names() []string {
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Of 返回数据库连接对应的方言，连接不是通过本包打开时返回错误
+func Of(db *gorm.DB) (Dialect, error) {
+	return Lookup(db.Dialector.Name())
+}
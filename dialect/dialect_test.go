@@ -0,0 +1,89 @@
+package dialect
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"mysql", "postgres", "sqlite"} {
+		d, err := Lookup(name)
+		if err != nil {
+			t.Fatalf("Lookup(%q) error = %v", name, err)
+		}
+		if d.Name() != name {
+			t.Errorf("Lookup(%q).Name() = %q", name, d.Name())
+		}
+		// Of 依赖 gorm Dialector.Name() 与方言名称一致
+		if got := d.Open("").Name(); got != name {
+			t.Errorf("Lookup(%q).Open().Name() = %q, want %q", name, got, name)
+		}
+	}
+	if _, err := Lookup("oracle"); err == nil {
+		t.Error("Lookup(\"oracle\") error = nil, want error")
+	}
+}
+
+func TestNames(t *testing.T) {
+	want := []string{"mysql", "postgres", "sqlite"}
+	if got := Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+// renamed 名称不在支持列表中的 gorm Dialector
+type renamed struct {
+	gorm.Dialector
+}
+
+func (renamed) Name() string {
+	return "oracle"
+}
+
+func TestOf(t *testing.T) {
+	sqlite, _ := Lookup("sqlite")
+	tests := []struct {
+		name      string
+		dialector gorm.Dialector
+		want      string
+		wantErr   bool
+	}{
+		{"sqlite", sqlite.Open("file::memory:"), "sqlite", false},
+		{"unsupported", renamed{sqlite.Open("file::memory:")}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &gorm.DB{Config: &gorm.Config{Dialector: tt.dialector}}
+			d, err := Of(db)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Of() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && d.Name() != tt.want {
+				t.Errorf("Of().Name() = %q, want %q", d.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipLocked(t *testing.T) {
+	skipLocked := []clause.Expression{clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}}
+	tests := []struct {
+		name string
+		want []clause.Expression
+	}{
+		{"mysql", skipLocked},
+		{"postgres", skipLocked},
+		{"sqlite", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, _ := Lookup(tt.name)
+			if got := d.SkipLocked(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SkipLocked() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
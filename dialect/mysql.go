@@ -0,0 +1,26 @@
+package dialect
+
+import (
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mysqlDialect struct{}
+
+func init() {
+	register(mysqlDialect{})
+}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) Open(dsn string) gorm.Dialector {
+	return mysql.Open(dsn)
+}
+
+// SkipLocked MySQL 8.0 起支持 SKIP LOCKED
+func (mysqlDialect) SkipLocked() []clause.Expression {
+	return []clause.Expression{clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}}
+}
@@ -0,0 +1,25 @@
+package dialect
+
+import (
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresDialect struct{}
+
+func init() {
+	register(postgresDialect{})
+}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) Open(dsn string) gorm.Dialector {
+	return postgres.Open(dsn)
+}
+
+func (postgresDialect) SkipLocked() []clause.Expression {
+	return []clause.Expression{clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}}
+}
@@ -0,0 +1,27 @@
+package dialect
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sqliteDialect struct{}
+
+func init() {
+	register(sqliteDialect{})
+}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+// Open 连接串示例：realworld.db、file::memory:?cache=shared
+func (sqliteDialect) Open(dsn string) gorm.Dialector {
+	return sqlite.Open(dsn)
+}
+
+// SkipLocked SQLite 没有行锁，写事务本身是串行的
+func (sqliteDialect) SkipLocked() []clause.Expression {
+	return nil
+}
@@ -0,0 +1,67 @@
+package lockout
+
+import (
+	"errors"
+	"goDemo/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// DBStore 保存在数据库中的失败记录，多个实例共享同一份计数
+type DBStore struct {
+	DB *gorm.DB
+}
+
+func (s *DBStore) Get(key string) (Record, error) {
+	var attempt models.LoginAttempt
+	err := s.DB.Where("attempt_key = ?", key).First(&attempt).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Record{}, nil
+		}
+		return Record{}, err
+	}
+	return Record{Failures: attempt.Failures, LastFailure: attempt.LastFailureAt}, nil
+}
+
+// Increment 以失败次数作为版本号做乐观更新，并发冲突时重试
+func (s *DBStore) Increment(key string, now time.Time, resetBefore time.Time) (Record, error) {
+	if err := s.DB.Where("last_failure_at < ?", resetBefore).Delete(&models.LoginAttempt{}).Error; err != nil {
+		return Record{}, err
+	}
+	var lastErr error
+	for i := 0; i < 5; i++ {
+		var attempt models.LoginAttempt
+		err := s.DB.Where("attempt_key = ?", key).First(&attempt).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			attempt = models.LoginAttempt{AttemptKey: key, Failures: 1, LastFailureAt: now}
+			// 唯一索引冲突说明其他实例刚刚创建了记录，重试时走更新分支
+			if lastErr = s.DB.Create(&attempt).Error; lastErr == nil {
+				return Record{Failures: 1, LastFailure: now}, nil
+			}
+			continue
+		}
+		if err != nil {
+			return Record{}, err
+		}
+		failures := attempt.Failures + 1
+		if attempt.LastFailureAt.Before(resetBefore) {
+			failures = 1
+		}
+		result := s.DB.Model(&models.LoginAttempt{}).
+			Where("id = ? AND failures = ?", attempt.ID, attempt.Failures).
+			Updates(map[string]interface{}{"failures": failures, "last_failure_at": now})
+		if result.Error != nil {
+			return Record{}, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return Record{Failures: failures, LastFailure: now}, nil
+		}
+		lastErr = errors.New("登录失败计数更新冲突")
+	}
+	return Record{}, lastErr
+}
+
+func (s *DBStore) Reset(key string) error {
+	return s.DB.Where("attempt_key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
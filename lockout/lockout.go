@@ -0,0 +1,151 @@
+package lockout
+
+import (
+	"fmt"
+	"goDemo/config"
+	"gorm.io/gorm"
+	"math"
+	"strings"
+	"time"
+)
+
+// Record 某个键（账号或 IP）的连续失败记录
+type Record struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// Store 失败记录的存储，多实例部署时需要使用共享的数据库实现
+type Store interface {
+	// Get 读取记录，不存在时返回零值
+	Get(key string) (Record, error)
+	// Increment 失败次数加一，上次失败早于 resetBefore 时从 1 重新计数
+	Increment(key string, now time.Time, resetBefore time.Time) (Record, error)
+	// Reset 清除记录
+	Reset(key string) error
+}
+
+// LockedError 尝试过于频繁或已被锁定
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("登录尝试次数过多，请 %d 秒后再试", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// Limiter 按账号和 IP 分别统计登录失败次数
+// 账号未达到阈值前，每次失败后需要等待的时间按 BaseDelay 指数增长（不超过 MaxDelay）；
+// IP 可能被多人共用（如 NAT），不做逐次等待，只在达到阈值后锁定。
+// 达到阈值后锁定 Duration，期间的尝试直接拒绝且不计数。距上次失败超过 Duration 后重新计数
+type Limiter struct {
+	Store            Store
+	MaxAttempts      int // 单个账号
+	MaxAttemptsPerIP int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	Duration         time.Duration
+}
+
+// Check 登录前检查账号和 IP 是否允许尝试，不允许时返回 *LockedError
+func (l *Limiter) Check(account string, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+	for _, k := range l.keys(account, ip) {
+		record, err := l.Store.Get(k.name)
+		if err != nil {
+			return err
+		}
+		wait = max(wait, l.wait(record, k, now))
+	}
+	if wait > 0 {
+		return &LockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Fail 记录一次失败
+func (l *Limiter) Fail(account string, ip string) error {
+	now := time.Now()
+	for _, k := range l.keys(account, ip) {
+		if _, err := l.Store.Increment(k.name, now, now.Add(-l.Duration)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Succeed 登录成功后清除账号的失败记录，IP 的记录保留，
+// 避免攻击者用自己的账号登录来清零同一 IP 上的计数
+func (l *Limiter) Succeed(account string) error {
+	return l.Unlock(account)
+}
+
+// Unlock 解除账号锁定，供管理员使用
+func (l *Limiter) Unlock(account string) error {
+	return l.Store.Reset(accountKey(account))
+}
+
+type limitKey struct {
+	name        string
+	maxAttempts int
+	backoff     bool
+}
+
+func (l *Limiter) keys(account string, ip string) []limitKey {
+	return []limitKey{
+		{accountKey(account), l.MaxAttempts, true},
+		{"ip:" + ip, l.MaxAttemptsPerIP, false},
+	}
+}
+
+// wait 距离下一次允许尝试还需等待的时间
+func (l *Limiter) wait(record Record, k limitKey, now time.Time) time.Duration {
+	if record.Failures == 0 || now.Sub(record.LastFailure) >= l.Duration {
+		return 0
+	}
+	var until time.Time
+	if record.Failures >= k.maxAttempts {
+		until = record.LastFailure.Add(l.Duration)
+	} else if k.backoff {
+		until = record.LastFailure.Add(l.backoff(record.Failures))
+	} else {
+		return 0
+	}
+	return max(until.Sub(now), 0)
+}
+
+// backoff 第 n 次失败后的等待时间：BaseDelay * 2^(n-1)
+func (l *Limiter) backoff(failures int) time.Duration {
+	delay := l.BaseDelay
+	for i := 1; i < failures && delay < l.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, l.MaxDelay)
+}
+
+// accountKey 账号按邮箱统计，忽略大小写，邮箱未注册时同样计数，避免据此探测账号是否存在
+func accountKey(account string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(account))
+}
+
+// New 根据配置创建登录限制器
+func New(cfg config.LockoutConfig, db *gorm.DB) (*Limiter, error) {
+	var store Store
+	switch cfg.Store {
+	case "database":
+		store = &DBStore{DB: db}
+	case "memory":
+		store = NewMemoryStore()
+	default:
+		return nil, fmt.Errorf("不支持的登录失败记录存储：%s", cfg.Store)
+	}
+	return &Limiter{
+		Store:            store,
+		MaxAttempts:      cfg.MaxAttempts,
+		MaxAttemptsPerIP: cfg.MaxAttemptsPerIP,
+		BaseDelay:        cfg.BaseDelay.Std(),
+		MaxDelay:         cfg.MaxDelay.Std(),
+		Duration:         cfg.Duration.Std(),
+	}, nil
+}
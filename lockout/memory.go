@@ -0,0 +1,53 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore 进程内存储，仅适用于单实例部署和测试
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+	sweptAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+func (s *MemoryStore) Get(key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *MemoryStore) Increment(key string, now time.Time, resetBefore time.Time) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 每分钟顺带清理一次已过期的记录，防止 map 无限增长
+	if now.Sub(s.sweptAt) > time.Minute {
+		for k, r := range s.records {
+			if r.LastFailure.Before(resetBefore) {
+				delete(s.records, k)
+			}
+		}
+		s.sweptAt = now
+	}
+	record := s.records[key]
+	// 清理之间过期的记录同样从 1 重新计数
+	if record.LastFailure.Before(resetBefore) {
+		record = Record{}
+	}
+	record.Failures++
+	record.LastFailure = now
+	s.records[key] = record
+	return record, nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
@@ -0,0 +1,83 @@
+package lockout
+
+import (
+	"goDemo/config"
+	"goDemo/migrations"
+	"gorm.io/gorm/logger"
+	"testing"
+	"time"
+)
+
+// stores 需要满足同一 Store 约定的各个实现
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+	db, err := config.InitDB(config.DatabaseConfig{Driver: "sqlite", DSN: config.Secret("file:lockout_" + t.Name() + "?mode=memory&cache=shared")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Store{"memory": NewMemoryStore(), "database": &DBStore{DB: db}}
+}
+
+func TestStoreIncrementResetsStaleRecord(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			for i := 1; i <= 3; i++ {
+				record, err := store.Increment("account:a@example.com", start, start.Add(-time.Hour))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if record.Failures != i {
+					t.Fatalf("Increment() failures = %d, want %d", record.Failures, i)
+				}
+			}
+			// 上次失败早于 resetBefore 时从 1 重新计数；30 秒内 MemoryStore 还没有清理过期记录
+			later := start.Add(30 * time.Second)
+			record, err := store.Increment("account:a@example.com", later, start.Add(time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if record.Failures != 1 {
+				t.Errorf("Increment() after window failures = %d, want 1", record.Failures)
+			}
+			got, err := store.Get("account:a@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Failures != 1 || !got.LastFailure.Equal(later) {
+				t.Errorf("Get() = %+v, want 1 failure at %v", got, later)
+			}
+		})
+	}
+}
+
+func TestStoreReset(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			if _, err := store.Increment("ip:192.0.2.1", now, now.Add(-time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Reset("ip:192.0.2.1"); err != nil {
+				t.Fatal(err)
+			}
+			got, err := store.Get("ip:192.0.2.1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Failures != 0 {
+				t.Errorf("Get() after Reset failures = %d, want 0", got.Failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer 把邮件写成 .eml 文件而不真正发送，用于本地开发
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o700); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000000"), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), format(m.From, msg), 0o600)
+}
+
+// sanitize 收件人地址只保留适合作为文件名的字符
+func sanitize(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_' || c == '@') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
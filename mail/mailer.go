@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"fmt"
+	"goDemo/config"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口，业务代码只依赖该接口，具体实现由配置决定
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New 根据配置创建邮件发送器
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return &SMTPMailer{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password.Value(),
+			From:     cfg.From,
+		}, nil
+	case "file":
+		return &FileMailer{Dir: cfg.Dir, From: cfg.From}, nil
+	case "memory":
+		return &MemoryMailer{}, nil
+	default:
+		return nil, fmt.Errorf("不支持的邮件发送方式：%s", cfg.Driver)
+	}
+}
+
+// format 按 RFC 5322 拼接邮件头和正文
+func format(from string, msg Message) []byte {
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		from, headerValue(msg.To), mime.QEncoding.Encode("UTF-8", headerValue(msg.Subject)), time.Now().Format(time.RFC1123Z), msg.Body))
+}
+
+// headerValue 去掉换行，防止通过收件人或主题注入额外的邮件头
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,25 @@
+package mail
+
+import (
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPMailer 通过 SMTP 服务器发送邮件，服务器支持时自动启用 STARTTLS
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(addr, auth, m.From, []string{msg.To}, format(m.From, msg))
+}
@@ -1,15 +1,27 @@
 package main
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"goDemo/config"
 	_ "goDemo/docs" // 导入生成的文档包
+	"goDemo/lockout"
+	"goDemo/mail"
+	"goDemo/migrations"
+	"goDemo/models"
+	"goDemo/oidc"
+	"goDemo/passwords"
+	"goDemo/policy"
 	"goDemo/route"
 	"goDemo/service"
 	"goDemo/utils"
+	"goDemo/webhook"
 	"log"
+	"os"
+	"strconv"
+	"time"
 )
 
 // @title RealWorld API
@@ -18,58 +30,267 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+// @description 登录令牌使用 "Bearer <token>"，个人 API 密钥使用 "Token <key>"
 // @host localhost:8080
 // @BasePath /api
 func main() {
 	//项目启动方式：
-	//1. 启动数据库服务，在config/sql_config处修改数据库连接URL
-	//2. 启动项目，访问 http://localhost:8080/swagger/index.html 查看API文档
-	//3. 注册账号，登录获取token，在Authorization处填写token，即可访问其他接口
-	//Tips：目前登录接口因为自己电脑不知名原因密码校验一直校验失败，
-	//因此注释了那段代码，只要输入正确用户名即可成功登录，登录获取token，然后访问其他接口
-	db, err := config.InitDB()
+	//1. 启动数据库服务，复制 config.example.yaml 为 config.yaml 并填写数据库连接串和JWT密钥，
+	//   也可以通过 REALWORLD_ 前缀的环境变量或命令行参数覆盖，例如 -config config.yaml -server.addr :9090
+	//2. 执行 go run . -config config.yaml migrate up 创建或升级表结构，
+	//   migrate status 查看迁移状态，migrate down [步数] 回滚
+	//3. 启动项目，访问 http://localhost:8080/swagger/index.html 查看API文档
+	//4. 注册账号，到 mail.dir 目录（默认 mailbox）下的邮件中找到验证链接完成邮箱验证，登录获取token，在Authorization处填写token，即可访问其他接口
+	//   registration.mode 为 invite_only 时，先执行 go run . -config config.yaml invite 生成邀请码再注册
+	//5. 执行 go run . -config config.yaml role <用户名> admin 设置管理员，之后可通过 /api/admin 管理其他用户的角色
+	//6. 注销账号默认由服务定期清理，也可以把 account.purge_interval 设为 0，改为定时执行 go run . -config config.yaml purge-accounts
+	//7. 定时发布的文章同样由服务定期发布，把 publishing.scheduler_interval 设为 0 后改为定时执行 go run . -config config.yaml publish-scheduled
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("配置加载失败：%v", err)
+	}
+	log.Printf("配置加载完成：%+v", *cfg)
+	db, err := config.InitDB(cfg.Database)
 	if err != nil {
 		log.Fatalf("数据库连接失败：%v", err)
 	}
-	//JWT密钥
-	auth := utils.NewAuth("DurRDDtjL2uB_Zyry4f6GHwoBgD5k7oLvC7Fj12E56E=")
+	// 数据库迁移子命令
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := migrations.Run(db, args[1:], os.Stdout); err != nil {
+			log.Fatalf("数据库迁移失败：%v", err)
+		}
+		return
+	}
+	if cfg.Database.AutoMigrate {
+		if _, err := migrations.Up(db); err != nil {
+			log.Fatalf("数据库迁移失败：%v", err)
+		}
+	} else if pending, err := migrations.Pending(db); err != nil {
+		log.Fatalf("读取迁移状态失败：%v", err)
+	} else if len(pending) > 0 {
+		log.Fatalf("%v（共 %d 个）", migrations.ErrPending, len(pending))
+	}
+	//JWT密钥环
+	keyring, err := utils.BuildKeyring(cfg.Auth)
+	if err != nil {
+		log.Fatalf("加载签名密钥失败：%v", err)
+	}
+	auth := utils.NewAuth(keyring, cfg.Auth.AccessTokenTTL.Std())
+	passwordPolicy, err := passwords.New(cfg.Auth.PasswordHashing)
+	if err != nil {
+		log.Fatalf("初始化密码哈希失败：%v", err)
+	}
+	passwordValidator, err := passwords.NewValidator(cfg.Auth.PasswordPolicy, passwordPolicy)
+	if err != nil {
+		log.Fatalf("初始化密码策略失败：%v", err)
+	}
 	// 初始化服务
+	invitationService := &service.InvitationService{
+		DB:          db,
+		Mode:        cfg.Registration.Mode,
+		TTL:         cfg.Registration.InvitationTTL.Std(),
+		UserLimit:   cfg.Registration.UserInvitations,
+		UserMaxUses: cfg.Registration.UserInvitationMaxUses,
+	}
 	userService := &service.UserService{
-		DB:   db,
-		Auth: auth,
+		DB:                db,
+		Auth:              auth,
+		Passwords:         passwordPolicy,
+		PasswordValidator: passwordValidator,
+		Invitations:       invitationService,
+	}
+	tokenService := &service.TokenService{
+		DB:         db,
+		Auth:       auth,
+		RefreshTTL: cfg.Auth.RefreshTokenTTL.Std(),
+	}
+	auth.Denylist = tokenService
+	auth.Sessions = tokenService
+	apiKeyService := &service.APIKeyService{
+		DB:         db,
+		MaxPerUser: cfg.Auth.APIKeys.MaxPerUser,
+		DefaultTTL: cfg.Auth.APIKeys.DefaultTTL.Std(),
+		MaxTTL:     cfg.Auth.APIKeys.MaxTTL.Std(),
+	}
+	auth.APIKeys = apiKeyService
+	verificationPolicy, err := policy.NewVerificationPolicy(cfg.Auth.UnverifiedRestrictions)
+	if err != nil {
+		log.Fatalf("auth.unverified_restrictions 无效：%v", err)
+	}
+	authMiddleware := &utils.AuthMiddleware{Auth: auth, LoadUser: userService.GetUserByID, Verification: verificationPolicy}
+	mailer, err := mail.New(cfg.Mail)
+	if err != nil {
+		log.Fatalf("初始化邮件发送失败：%v", err)
+	}
+	passwordResetService := &service.PasswordResetService{
+		DB:        db,
+		Mailer:    mailer,
+		Tokens:    tokenService,
+		Passwords: passwordPolicy,
+		Validator: passwordValidator,
+		TTL:       cfg.Auth.PasswordResetTTL.Std(),
+		PublicURL: cfg.Server.PublicURL,
+	}
+	verificationService := &service.VerificationService{
+		DB:             db,
+		Auth:           auth,
+		Mailer:         mailer,
+		TTL:            cfg.Auth.EmailVerificationTTL.Std(),
+		ResendInterval: cfg.Auth.VerificationResendInterval.Std(),
+		PublicURL:      cfg.Server.PublicURL,
+	}
+	twoFactorService := &service.TwoFactorService{
+		DB:           db,
+		Auth:         auth,
+		Issuer:       cfg.Auth.TwoFactorIssuer,
+		ChallengeTTL: cfg.Auth.TwoFactorChallengeTTL.Std(),
+	}
+	oidcService := &service.OIDCService{
+		DB:          db,
+		Providers:   oidc.NewProviders(cfg.Auth.OIDC, cfg.Server.PublicURL),
+		StateTTL:    cfg.Auth.OIDC.StateTTL.Std(),
+		Invitations: invitationService,
+	}
+	loginLimiter, err := lockout.New(cfg.Auth.Lockout, db)
+	if err != nil {
+		log.Fatalf("初始化登录限制失败：%v", err)
 	}
 	profileService := &service.ProfileService{
-		DB: db,
+		DB:      db,
+		Lockout: loginLimiter,
 	}
 	articleService := &service.ArticleService{
-		DB: db,
+		DB:           db,
+		Verification: verificationPolicy,
+		Listener: &service.PublishNotifier{
+			DB:              db,
+			Mailer:          mailer,
+			Webhooks:        webhook.New(cfg.Publishing.Webhooks),
+			NotifyFollowers: cfg.Publishing.NotifyFollowers,
+			PublicURL:       cfg.Server.PublicURL,
+		},
+	}
+	tagService := &service.TagService{DB: db}
+	revisionService := &service.RevisionService{
+		DB:       db,
+		Articles: articleService,
+	}
+	accountService := &service.AccountService{
+		DB:             db,
+		Tokens:         tokenService,
+		GracePeriod:    cfg.Account.DeletionGracePeriod.Std(),
+		DeletedContent: cfg.Account.DeletedContent,
+	}
+	impersonationService := &service.ImpersonationService{
+		DB:     db,
+		Auth:   auth,
+		Tokens: tokenService,
+		TTL:    cfg.Auth.ImpersonationTTL.Std(),
+	}
+	authMiddleware.Audit = impersonationService
+	// 清理到期注销账号子命令，适合由定时任务调用
+	if len(args) > 0 && args[0] == "purge-accounts" {
+		purged, err := accountService.PurgeDue(time.Now())
+		if err != nil {
+			log.Fatalf("清理注销账号失败（已清理 %d 个）：%v", purged, err)
+		}
+		log.Printf("已清理 %d 个注销账号", purged)
+		return
+	}
+	// 发布到期定时文章子命令，适合由定时任务调用，通知发送完才退出
+	if len(args) > 0 && args[0] == "publish-scheduled" {
+		published, err := articleService.PublishDue(time.Now())
+		if err != nil {
+			log.Fatalf("定时发布文章失败：%v", err)
+		}
+		log.Printf("已定时发布 %d 篇文章", published)
+		return
+	}
+	// 分配角色子命令，用于初始化第一个管理员
+	if len(args) > 0 && args[0] == "role" {
+		if len(args) != 3 {
+			log.Fatalf("用法：role <用户名> <user|moderator|admin>")
+		}
+		if _, err := profileService.AssignRole(args[1], args[2]); err != nil {
+			log.Fatalf("分配角色失败：%v", err)
+		}
+		log.Printf("已将用户 %s 的角色设置为 %s", args[1], args[2])
+		return
+	}
+	// 创建邀请码子命令，仅限邀请注册时用于邀请第一批用户
+	if len(args) > 0 && args[0] == "invite" {
+		var request models.InvitationRequest
+		if len(args) > 2 {
+			log.Fatalf("用法：invite [使用次数]")
+		}
+		if len(args) == 2 {
+			maxUses, err := strconv.Atoi(args[1])
+			if err != nil || maxUses <= 0 {
+				log.Fatalf("使用次数必须是正整数")
+			}
+			request.Invitation.MaxUses = maxUses
+		}
+		invitation, code, err := invitationService.Create(nil, &request)
+		if err != nil {
+			log.Fatalf("创建邀请码失败：%v", err)
+		}
+		fmt.Println(code)
+		log.Printf("邀请码可使用 %d 次，过期时间：%v", invitation.MaxUses, invitation.ExpiresAt)
+		return
+	}
+	if interval := cfg.Account.PurgeInterval.Std(); interval > 0 {
+		go accountService.RunPurger(interval)
+	}
+	if interval := cfg.Publishing.SchedulerInterval.Std(); interval > 0 {
+		go articleService.RunScheduler(interval)
 	}
 	router := gin.Default()
-	router.Use(utils.CORSMiddleware())
+	// 客户端 IP 用于登录失败按 IP 计数，只采信可信代理转发的 X-Forwarded-For
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("server.trusted_proxies 无效：%v", err)
+	}
+	router.Use(utils.CORSMiddleware(cfg.CORS))
 	// 注册 Swagger 路由
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// 注册路由
-	route.SetupRoutes(router, userService, auth)
-	route.LoginRoutes(router, userService, auth)
-	route.GetCurrentUserRoutes(router, userService, auth)
-	route.UpdateUserRoutes(router, userService, auth)
-	route.GetProfileRoutes(router, profileService, userService, auth)
-	route.FollowUserRoutes(router, profileService, userService, auth)
-	route.UnfollowUserRoutes(router, profileService, userService, auth)
-	route.ListArticlesRoutes(router, articleService, auth)
-	route.FeedArticlesRoutes(router, articleService, auth)
-	route.GetArticleRoutes(router, articleService, auth)
-	route.CreateArticleRoutes(router, articleService, auth)
-	route.UpdateArticleRoutes(router, articleService, auth)
-	route.DeleteArticleRoutes(router, articleService, auth)
-	route.AddCommentRoutes(router, articleService, auth)
-	route.GetCommentsRoutes(router, articleService, auth)
-	route.DeleteCommentRoutes(router, articleService, auth)
-	route.FavoriteArticleRoutes(router, articleService, auth)
-	route.UnfavoriteArticleRoutes(router, articleService, auth)
+	route.JWKSRoutes(router, auth)
+	route.SetupRoutes(router, userService, verificationService)
+	route.LoginRoutes(router, userService, tokenService, twoFactorService, loginLimiter)
+	route.GetCurrentUserRoutes(router, userService, authMiddleware)
+	route.UpdateUserRoutes(router, userService, tokenService, verificationService, authMiddleware)
+	route.RefreshTokenRoutes(router, userService, tokenService)
+	route.LogoutRoutes(router, userService, tokenService, authMiddleware)
+	route.SessionRoutes(router, tokenService, authMiddleware)
+	route.PasswordResetRoutes(router, passwordResetService)
+	route.VerifyEmailRoutes(router, verificationService, authMiddleware)
+	route.TwoFactorRoutes(router, twoFactorService, loginLimiter, authMiddleware)
+	route.AccountRoutes(router, accountService, authMiddleware)
+	route.InvitationRoutes(router, invitationService, authMiddleware)
+	route.APIKeyRoutes(router, apiKeyService, authMiddleware)
+	route.OIDCRoutes(router, oidcService, tokenService, twoFactorService, authMiddleware)
+	route.GetProfileRoutes(router, profileService, userService, authMiddleware)
+	route.FollowUserRoutes(router, profileService, userService, authMiddleware)
+	route.UnfollowUserRoutes(router, profileService, userService, authMiddleware)
+	route.ListArticlesRoutes(router, articleService, authMiddleware)
+	route.FeedArticlesRoutes(router, articleService, authMiddleware)
+	route.TagRoutes(router, tagService)
+	route.GetArticleRoutes(router, articleService, authMiddleware)
+	route.CreateArticleRoutes(router, articleService, authMiddleware)
+	route.UpdateArticleRoutes(router, articleService, authMiddleware)
+	route.PublishArticleRoutes(router, articleService, authMiddleware)
+	route.UserArticlesRoutes(router, articleService, authMiddleware)
+	route.RevisionRoutes(router, revisionService, authMiddleware)
+	route.DeleteArticleRoutes(router, articleService, authMiddleware)
+	route.AddCommentRoutes(router, articleService, authMiddleware)
+	route.GetCommentsRoutes(router, articleService, authMiddleware)
+	route.DeleteCommentRoutes(router, articleService, authMiddleware)
+	route.FavoriteArticleRoutes(router, articleService, authMiddleware)
+	route.UnfavoriteArticleRoutes(router, articleService, authMiddleware)
+	route.AdminRoutes(router, profileService, authMiddleware)
+	route.ImpersonationRoutes(router, impersonationService, authMiddleware)
 
-	if err := router.Run(":8080"); err != nil {
+	if err := router.Run(cfg.Server.Addr); err != nil {
 		log.Fatalf("服务器启动失败：%v", err)
 	}
 }
@@ -0,0 +1,71 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// 初始表结构，与原先启动时 AutoMigrate 生成的结构保持一致，
+// 已有数据库执行时只会补齐缺失的表和列，不会破坏现有数据
+
+type user0001 struct {
+	gorm.Model
+	Username string `gorm:"size:255;unique;not null"`
+	Email    string `gorm:"size:255;unique;not null"`
+	Password string `gorm:"text;not null;column:password"`
+	Bio      string `gorm:"text"`
+	Image    string `gorm:"size:255"`
+}
+
+func (user0001) TableName() string { return "user_models" }
+
+type follow0001 struct {
+	gorm.Model
+	Follower uint `gorm:"index;not null"`
+	Followed uint `gorm:"index;not null"`
+}
+
+func (follow0001) TableName() string { return "follows" }
+
+type article0001 struct {
+	gorm.Model
+	Slug           string `gorm:"type:varchar(255);uniqueIndex;not null"`
+	Title          string `gorm:"not null"`
+	Description    string
+	Body           string `gorm:"not null"`
+	TagList        string `gorm:"type:json"`
+	Favorited      bool
+	FavoritesCount int
+	AuthorID       uint     `gorm:"not null"`
+	Author         user0001 `gorm:"foreignKey:AuthorID"`
+}
+
+func (article0001) TableName() string { return "articles" }
+
+type comment0001 struct {
+	gorm.Model
+	Body      string   `gorm:"not null"`
+	AuthorID  uint     `gorm:"not null"`
+	Author    user0001 `gorm:"foreignKey:AuthorID"`
+	ArticleID uint     `gorm:"not null"`
+}
+
+func (comment0001) TableName() string { return "comments" }
+
+type favorite0001 struct {
+	gorm.Model
+	UserID    uint `gorm:"not null;index"`
+	ArticleID uint `gorm:"not null;index"`
+}
+
+func (favorite0001) TableName() string { return "favorites" }
+
+func init() {
+	register(Migration{
+		Version: "0001",
+		Name:    "create_initial_tables",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&user0001{}, &follow0001{}, &article0001{}, &comment0001{}, &favorite0001{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&favorite0001{}, &comment0001{}, &article0001{}, &follow0001{}, &user0001{})
+		},
+	})
+}
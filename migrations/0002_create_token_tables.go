@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type refreshToken0002 struct {
+	gorm.Model
+	UserID    uint      `gorm:"not null;index"`
+	FamilyID  string    `gorm:"size:64;not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+func (refreshToken0002) TableName() string { return "refresh_tokens" }
+
+type revokedToken0002 struct {
+	gorm.Model
+	JTI       string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+func (revokedToken0002) TableName() string { return "revoked_tokens" }
+
+func init() {
+	register(Migration{
+		Version: "0002",
+		Name:    "create_token_tables",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&refreshToken0002{}, &revokedToken0002{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&revokedToken0002{}, &refreshToken0002{})
+		},
+	})
+}
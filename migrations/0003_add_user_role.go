@@ -0,0 +1,22 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type user0003 struct {
+	Role string `gorm:"size:32;not null;default:user"`
+}
+
+func (user0003) TableName() string { return "user_models" }
+
+func init() {
+	register(Migration{
+		Version: "0003",
+		Name:    "add_user_role",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&user0003{}, "Role")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumn(tx, &user0003{}, "Role")
+		},
+	})
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type passwordResetToken0004 struct {
+	gorm.Model
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+}
+
+func (passwordResetToken0004) TableName() string { return "password_reset_tokens" }
+
+func init() {
+	register(Migration{
+		Version: "0004",
+		Name:    "create_password_reset_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&passwordResetToken0004{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&passwordResetToken0004{})
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0005 struct {
+	EmailVerifiedAt    *time.Time
+	VerificationSentAt *time.Time
+}
+
+func (user0005) TableName() string { return "user_models" }
+
+func init() {
+	register(Migration{
+		Version: "0005",
+		Name:    "add_email_verification",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"EmailVerifiedAt", "VerificationSentAt"} {
+				if err := tx.Migrator().AddColumn(&user0005{}, column); err != nil {
+					return err
+				}
+			}
+			// 已有账号视为已验证，避免上线后被突然限制
+			return tx.Table("user_models").Where("email_verified_at IS NULL").
+				Update("email_verified_at", gorm.Expr("created_at")).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"VerificationSentAt", "EmailVerifiedAt"} {
+				if err := dropColumn(tx, &user0005{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0006 struct {
+	TOTPSecret      string `gorm:"size:64"`
+	TOTPEnabledAt   *time.Time
+	TOTPLastCounter int64 `gorm:"not null;default:0"`
+}
+
+func (user0006) TableName() string { return "user_models" }
+
+type recoveryCode0006 struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	CodeHash string `gorm:"size:64;not null;uniqueIndex"`
+	UsedAt   *time.Time
+}
+
+func (recoveryCode0006) TableName() string { return "recovery_codes" }
+
+func init() {
+	register(Migration{
+		Version: "0006",
+		Name:    "add_two_factor",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"TOTPSecret", "TOTPEnabledAt", "TOTPLastCounter"} {
+				if err := tx.Migrator().AddColumn(&user0006{}, column); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().CreateTable(&recoveryCode0006{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&recoveryCode0006{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"TOTPLastCounter", "TOTPEnabledAt", "TOTPSecret"} {
+				if err := dropColumn(tx, &user0006{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type loginAttempt0007 struct {
+	ID            uint      `gorm:"primarykey"`
+	AttemptKey    string    `gorm:"size:191;not null;uniqueIndex"`
+	Failures      int       `gorm:"not null"`
+	LastFailureAt time.Time `gorm:"not null;index"`
+}
+
+func (loginAttempt0007) TableName() string { return "login_attempts" }
+
+func init() {
+	register(Migration{
+		Version: "0007",
+		Name:    "create_login_attempts",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&loginAttempt0007{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&loginAttempt0007{})
+		},
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type apiKey0008 struct {
+	gorm.Model
+	UserID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"size:100;not null"`
+	Prefix     string `gorm:"size:16;not null"`
+	KeyHash    string `gorm:"size:64;not null;uniqueIndex"`
+	Scopes     string `gorm:"size:255;not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+func (apiKey0008) TableName() string { return "api_keys" }
+
+func init() {
+	register(Migration{
+		Version: "0008",
+		Name:    "create_api_keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&apiKey0008{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&apiKey0008{})
+		},
+	})
+}
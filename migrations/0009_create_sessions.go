@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type session0009 struct {
+	gorm.Model
+	UserID       uint      `gorm:"not null;index"`
+	FamilyID     string    `gorm:"size:64;not null;uniqueIndex"`
+	UserAgent    string    `gorm:"size:255"`
+	IP           string    `gorm:"size:64"`
+	LastActiveAt time.Time `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	RevokedAt    *time.Time
+}
+
+func (session0009) TableName() string { return "sessions" }
+
+func init() {
+	register(Migration{
+		Version: "0009",
+		Name:    "create_sessions",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&session0009{}); err != nil {
+				return err
+			}
+			// 为仍然有效的刷新令牌家族补建会话，否则升级后所有已登录用户都会被强制退出
+			// 聚合在内存中完成，SQLite 对 MIN/MAX 的时间结果不保留列类型，无法直接扫描为 time.Time
+			var tokens []struct {
+				UserID    uint
+				FamilyID  string
+				CreatedAt time.Time
+				ExpiresAt time.Time
+			}
+			err := tx.Table("refresh_tokens").
+				Select("user_id, family_id, created_at, expires_at").
+				Where("revoked_at IS NULL AND expires_at > ? AND deleted_at IS NULL", time.Now()).
+				Order("id").
+				Scan(&tokens).Error
+			if err != nil {
+				return err
+			}
+			sessions := make(map[string]*session0009)
+			var order []string
+			for _, token := range tokens {
+				session, ok := sessions[token.FamilyID]
+				if !ok {
+					session = &session0009{UserID: token.UserID, FamilyID: token.FamilyID}
+					session.CreatedAt = token.CreatedAt
+					sessions[token.FamilyID] = session
+					order = append(order, token.FamilyID)
+				}
+				session.LastActiveAt = token.CreatedAt
+				session.ExpiresAt = token.ExpiresAt
+			}
+			for _, familyID := range order {
+				if err := tx.Create(sessions[familyID]).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&session0009{})
+		},
+	})
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userIdentity0010 struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index"`
+	Provider    string `gorm:"size:64;not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject     string `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject"`
+	Email       string `gorm:"size:255"`
+	LastLoginAt *time.Time
+}
+
+func (userIdentity0010) TableName() string { return "user_identities" }
+
+type oidcLoginState0010 struct {
+	gorm.Model
+	StateHash    string `gorm:"size:64;not null;uniqueIndex"`
+	Provider     string `gorm:"size:64;not null"`
+	CodeVerifier string `gorm:"size:128;not null"`
+	Nonce        string `gorm:"size:64;not null"`
+	LinkUserID   *uint
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	UsedAt       *time.Time
+}
+
+func (oidcLoginState0010) TableName() string { return "oidc_login_states" }
+
+func init() {
+	register(Migration{
+		Version: "0010",
+		Name:    "create_user_identities",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&userIdentity0010{}, &oidcLoginState0010{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&oidcLoginState0010{}, &userIdentity0010{})
+		},
+	})
+}
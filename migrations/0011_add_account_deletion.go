@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0011 struct {
+	DeletionScheduledAt *time.Time `gorm:"index"`
+}
+
+func (user0011) TableName() string { return "user_models" }
+
+func init() {
+	register(Migration{
+		Version: "0011",
+		Name:    "add_account_deletion",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&user0011{}, "DeletionScheduledAt"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateIndex(&user0011{}, "DeletionScheduledAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex(&user0011{}, "DeletionScheduledAt"); err != nil {
+				return err
+			}
+			return dropColumn(tx, &user0011{}, "DeletionScheduledAt")
+		},
+	})
+}
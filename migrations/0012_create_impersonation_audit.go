@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type impersonation0012 struct {
+	gorm.Model
+	ActorID         uint      `gorm:"not null;index"`
+	ActorUsername   string    `gorm:"size:255;not null"`
+	SubjectID       uint      `gorm:"not null;index"`
+	SubjectUsername string    `gorm:"size:255;not null"`
+	Reason          string    `gorm:"size:255;not null"`
+	TokenID         string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt       time.Time `gorm:"not null"`
+	EndedAt         *time.Time
+}
+
+func (impersonation0012) TableName() string { return "impersonations" }
+
+type auditLog0012 struct {
+	ID              uint      `gorm:"primarykey"`
+	CreatedAt       time.Time `gorm:"index"`
+	Action          string    `gorm:"size:32;not null;index"`
+	ActorID         uint      `gorm:"not null;index"`
+	ActorUsername   string    `gorm:"size:255;not null"`
+	SubjectID       uint      `gorm:"index"`
+	SubjectUsername string    `gorm:"size:255"`
+	ImpersonationID *uint     `gorm:"index"`
+	Method          string    `gorm:"size:16"`
+	Path            string    `gorm:"size:255"`
+	Status          int
+	IP              string `gorm:"size:64"`
+	Detail          string `gorm:"size:255"`
+}
+
+func (auditLog0012) TableName() string { return "audit_logs" }
+
+func init() {
+	register(Migration{
+		Version: "0012",
+		Name:    "create_impersonation_audit",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&impersonation0012{}, &auditLog0012{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&auditLog0012{}, &impersonation0012{})
+		},
+	})
+}
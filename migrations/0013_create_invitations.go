@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type invitation0013 struct {
+	gorm.Model
+	InviterID *uint  `gorm:"index"`
+	CodeHash  string `gorm:"size:64;not null;uniqueIndex"`
+	Prefix    string `gorm:"size:16;not null"`
+	Note      string `gorm:"size:255"`
+	MaxUses   int    `gorm:"not null;default:1"`
+	UseCount  int    `gorm:"not null;default:0"`
+	ExpiresAt *time.Time
+	RevokedAt *time.Time
+}
+
+func (invitation0013) TableName() string { return "invitations" }
+
+type user0013 struct {
+	InvitedByID  *uint `gorm:"index"`
+	InvitationID *uint `gorm:"index"`
+}
+
+func (user0013) TableName() string { return "user_models" }
+
+func init() {
+	register(Migration{
+		Version: "0013",
+		Name:    "create_invitations",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&invitation0013{}); err != nil {
+				return err
+			}
+			for _, field := range []string{"InvitedByID", "InvitationID"} {
+				if err := tx.Migrator().AddColumn(&user0013{}, field); err != nil {
+					return err
+				}
+				if err := tx.Migrator().CreateIndex(&user0013{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			fields := []string{"InvitationID", "InvitedByID"}
+			for _, field := range fields {
+				if err := tx.Migrator().DropIndex(&user0013{}, field); err != nil {
+					return err
+				}
+			}
+			for _, field := range fields {
+				if err := dropColumn(tx, &user0013{}, field); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable(&invitation0013{})
+		},
+	})
+}
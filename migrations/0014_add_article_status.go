@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type article0014 struct {
+	Status      string     `gorm:"type:varchar(16);not null;default:published;index"`
+	PublishedAt *time.Time `gorm:"index"`
+}
+
+func (article0014) TableName() string { return "articles" }
+
+func init() {
+	register(Migration{
+		Version: "0014",
+		Name:    "add_article_status",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"Status", "PublishedAt"} {
+				if err := tx.Migrator().AddColumn(&article0014{}, field); err != nil {
+					return err
+				}
+				if err := tx.Migrator().CreateIndex(&article0014{}, field); err != nil {
+					return err
+				}
+			}
+			// 已有文章都是直接发布的，以创建时间作为发布时间
+			return tx.Exec("UPDATE articles SET published_at = created_at WHERE published_at IS NULL").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			fields := []string{"PublishedAt", "Status"}
+			for _, field := range fields {
+				if err := tx.Migrator().DropIndex(&article0014{}, field); err != nil {
+					return err
+				}
+			}
+			for _, field := range fields {
+				if err := dropColumn(tx, &article0014{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
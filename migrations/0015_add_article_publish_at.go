@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type article0015 struct {
+	PublishAt *time.Time `gorm:"index"`
+}
+
+func (article0015) TableName() string { return "articles" }
+
+func init() {
+	register(Migration{
+		Version: "0015",
+		Name:    "add_article_publish_at",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&article0015{}, "PublishAt"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateIndex(&article0015{}, "PublishAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			// 回滚后没有定时发布，尚未发布的定时文章改回草稿
+			if err := tx.Exec("UPDATE articles SET status = 'draft' WHERE status = 'scheduled'").Error; err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropIndex(&article0015{}, "PublishAt"); err != nil {
+				return err
+			}
+			return dropColumn(tx, &article0015{}, "PublishAt")
+		},
+	})
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type articleRevision0016 struct {
+	ID           uint      `gorm:"primarykey"`
+	CreatedAt    time.Time `gorm:"not null"`
+	ArticleID    uint      `gorm:"not null;uniqueIndex:idx_article_revisions_number"`
+	Number       int       `gorm:"not null;uniqueIndex:idx_article_revisions_number"`
+	EditorID     uint      `gorm:"not null;index"`
+	Title        string    `gorm:"not null"`
+	Description  string
+	Body         string `gorm:"not null"`
+	TagList      string `gorm:"type:json"`
+	RestoredFrom *int
+}
+
+func (articleRevision0016) TableName() string { return "article_revisions" }
+
+func init() {
+	register(Migration{
+		Version: "0016",
+		Name:    "create_article_revisions",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&articleRevision0016{}); err != nil {
+				return err
+			}
+			// 已有文章以当前内容作为第一个版本，之前的修改无从追溯
+			return tx.Exec(`INSERT INTO article_revisions (created_at, article_id, number, editor_id, title, description, body, tag_list)
+				SELECT updated_at, id, 1, author_id, title, description, body, tag_list FROM articles`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&articleRevision0016{})
+		},
+	})
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type article0017 struct {
+	SlugPinned bool `gorm:"not null;default:false"`
+}
+
+func (article0017) TableName() string { return "articles" }
+
+type articleSlug0017 struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"not null"`
+	Slug      string    `gorm:"type:varchar(255);uniqueIndex;not null"`
+	ArticleID uint      `gorm:"not null;index"`
+}
+
+func (articleSlug0017) TableName() string { return "article_slugs" }
+
+func init() {
+	register(Migration{
+		Version: "0017",
+		Name:    "create_article_slugs",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&article0017{}, "SlugPinned"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&articleSlug0017{})
+		},
+		Down: func(tx *gorm.DB) error {
+			// 回滚后旧链接不再跳转
+			if err := tx.Migrator().DropTable(&articleSlug0017{}); err != nil {
+				return err
+			}
+			return dropColumn(tx, &article0017{}, "SlugPinned")
+		},
+	})
+}
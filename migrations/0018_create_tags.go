@@ -0,0 +1,115 @@
+package migrations
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tag0018 struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"not null"`
+	Name      string    `gorm:"type:varchar(64);uniqueIndex;not null"`
+}
+
+func (tag0018) TableName() string { return "tags" }
+
+type articleTag0018 struct {
+	ArticleID uint `gorm:"primaryKey;autoIncrement:false"`
+	TagID     uint `gorm:"primaryKey;autoIncrement:false;index"`
+}
+
+func (articleTag0018) TableName() string { return "article_tags" }
+
+type article0018 struct {
+	ID      uint
+	TagList *string
+}
+
+func (article0018) TableName() string { return "articles" }
+
+// normalizeTags0018 迁移时的标签规范化规则（NFKC、合并空白、转小写、去重），超长的标签截断；
+// 与 service.NormalizeTags 分开维护，之后规则变化不影响已执行的迁移
+func normalizeTags0018(names []string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		tag := strings.ToLower(strings.Join(strings.Fields(norm.NFKC.String(name)), " "))
+		if runes := []rune(tag); len(runes) > 64 {
+			tag = strings.TrimSpace(string(runes[:64]))
+		}
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// backfillTags0018 规范化已有文章（包括已删除的）的标签列表，并建立标签关联
+func backfillTags0018(tx *gorm.DB) error {
+	var articles []article0018
+	return tx.Model(&article0018{}).Order("id").FindInBatches(&articles, 200, func(batch *gorm.DB, _ int) error {
+		for _, article := range articles {
+			var names []string
+			if article.TagList != nil {
+				if err := json.Unmarshal([]byte(*article.TagList), &names); err != nil {
+					return err
+				}
+			}
+			tags := normalizeTags0018(names)
+			list, err := json.Marshal(tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&article0018{}).Where("id = ?", article.ID).Update("tag_list", string(list)).Error; err != nil {
+				return err
+			}
+			if len(tags) == 0 {
+				continue
+			}
+			rows := make([]tag0018, len(tags))
+			for i, tag := range tags {
+				rows[i] = tag0018{Name: tag}
+			}
+			err = tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&rows).Error
+			if err != nil {
+				return err
+			}
+			var ids []uint
+			if err := tx.Model(&tag0018{}).Where("name IN ?", tags).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			links := make([]articleTag0018, len(ids))
+			for i, id := range ids {
+				links[i] = articleTag0018{ArticleID: article.ID, TagID: id}
+			}
+			if err := tx.Create(&links).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func init() {
+	register(Migration{
+		Version: "0018",
+		Name:    "create_tags",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&tag0018{}, &articleTag0018{}); err != nil {
+				return err
+			}
+			return backfillTags0018(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			// 文章的标签列表保持规范化后的内容
+			return tx.Migrator().DropTable(&articleTag0018{}, &tag0018{})
+		},
+	})
+}
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+const usage = "用法：migrate up | migrate down [步数，默认 1] | migrate status"
+
+// Run 执行 migrate 子命令
+func Run(db *gorm.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "up":
+		done, err := Up(db)
+		for _, m := range done {
+			fmt.Fprintf(out, "已执行 %s_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Fprintln(out, "没有需要执行的迁移")
+		}
+		return nil
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("无效的回滚步数：%s", args[1])
+			}
+			steps = n
+		}
+		rolledBack, err := Down(db, steps)
+		for _, m := range rolledBack {
+			fmt.Fprintf(out, "已回滚 %s_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(rolledBack) == 0 {
+			fmt.Fprintln(out, "没有可以回滚的迁移")
+		}
+		return nil
+	case "status":
+		statuses, err := StatusOf(db)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			state, appliedAt := "pending", "-"
+			if s.AppliedAt != nil {
+				state, appliedAt = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Unknown {
+				state = "unknown"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.New(usage)
+	}
+}
@@ -0,0 +1,209 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Migration 一次版本化的结构变更
+// Up/Down 只能使用迁移文件内定义的结构体快照，不要直接引用 models 包，
+// 否则模型后续变化会悄悄改变历史迁移的行为
+type Migration struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration 记录已执行的迁移
+type SchemaMigration struct {
+	Version   string    `gorm:"primaryKey;size:64"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status 单个迁移的执行状态
+type Status struct {
+	Version   string
+	Name      string
+	AppliedAt *time.Time
+	// Unknown 表示数据库中有记录，但代码中已找不到对应的迁移
+	Unknown bool
+}
+
+var registry []Migration
+
+// register 注册迁移，由各迁移文件在 init 中调用
+func register(m Migration) {
+	for _, existing := range registry {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("迁移版本重复：%s", m.Version))
+		}
+	}
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool {
+		return registry[i].Version < registry[j].Version
+	})
+}
+
+// All 返回按版本排序的全部迁移
+func All() []Migration {
+	return append([]Migration(nil), registry...)
+}
+
+// applied 读取已执行的迁移记录
+func applied(db *gorm.DB) (map[string]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	var records []SchemaMigration
+	if err := db.Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]SchemaMigration, len(records))
+	for _, r := range records {
+		result[r.Version] = r
+	}
+	return result, nil
+}
+
+// Pending 返回尚未执行的迁移
+func Pending(db *gorm.DB) ([]Migration, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range registry {
+		if _, ok := done[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up 按版本顺序执行所有未执行的迁移
+func Up(db *gorm.DB) ([]Migration, error) {
+	pending, err := Pending(db)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, m := range pending {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("执行迁移 %s_%s 失败：%w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0, len(done))
+	for v := range done {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+
+	var rolledBack []Migration
+	for _, v := range versions {
+		if len(rolledBack) >= steps {
+			break
+		}
+		m, ok := find(v)
+		if !ok {
+			return rolledBack, fmt.Errorf("找不到版本 %s 对应的迁移，无法回滚", v)
+		}
+		if m.Down == nil {
+			return rolledBack, fmt.Errorf("迁移 %s_%s 不支持回滚", m.Version, m.Name)
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("回滚迁移 %s_%s 失败：%w", m.Version, m.Name, err)
+		}
+		rolledBack = append(rolledBack, m)
+	}
+	return rolledBack, nil
+}
+
+// StatusOf 返回全部迁移以及数据库中未知版本的执行状态
+func StatusOf(db *gorm.DB) ([]Status, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	var result []Status
+	for _, m := range registry {
+		s := Status{Version: m.Version, Name: m.Name}
+		if r, ok := done[m.Version]; ok {
+			appliedAt := r.AppliedAt
+			s.AppliedAt = &appliedAt
+			delete(done, m.Version)
+		}
+		result = append(result, s)
+	}
+	for _, r := range done {
+		appliedAt := r.AppliedAt
+		result = append(result, Status{Version: r.Version, Name: r.Name, AppliedAt: &appliedAt, Unknown: true})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+func find(version string) (Migration, bool) {
+	for _, m := range registry {
+		if m.Version == version {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+// dropColumn 删除列，Down 中删除列都应使用它而不是 Migrator().DropColumn。
+// SQLite 驱动的 DropColumn 通过重建表实现：写在建表语句里的唯一约束会保留，
+// 但单独创建的普通索引（例如 idx_user_models_deleted_at）会全部丢失。
+// 因此 SQLite 下直接使用 3.35 起支持的 ALTER TABLE DROP COLUMN；被索引的列需要先删除索引
+func dropColumn(tx *gorm.DB, model interface{}, field string) error {
+	if tx.Dialector.Name() != "sqlite" {
+		return tx.Migrator().DropColumn(model, field)
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	column := field
+	if f := stmt.Schema.LookUpField(field); f != nil {
+		column = f.DBName
+	}
+	return tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: stmt.Table}, clause.Column{Name: column}).Error
+}
+
+// ErrPending 启动时存在未执行的迁移
+var ErrPending = errors.New("存在未执行的数据库迁移，请先执行 migrate up")
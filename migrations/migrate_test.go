@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"goDemo/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 为每个测试创建独立的 SQLite 内存数据库，不执行迁移
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", url.PathEscape(t.Name()))
+	db, err := config.InitDB(config.DatabaseConfig{Driver: "sqlite", DSN: config.Secret(dsn)})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败：%v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// indexes 返回表上的全部索引名，包括唯一约束自动创建的索引
+func indexes(t *testing.T, db *gorm.DB, table string) []string {
+	t.Helper()
+	var names []string
+	err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ? ORDER BY name", table).
+		Scan(&names).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+type dropColumnUser struct {
+	gorm.Model
+	Email string `gorm:"size:255;not null;uniqueIndex"`
+	Note  string `gorm:"size:255"`
+}
+
+func TestDropColumnKeepsIndexes(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Migrator().CreateTable(&dropColumnUser{}); err != nil {
+		t.Fatal(err)
+	}
+	before := indexes(t, db, "drop_column_users")
+	if err := dropColumn(db, &dropColumnUser{}, "Note"); err != nil {
+		t.Fatalf("dropColumn() error = %v", err)
+	}
+	if db.Migrator().HasColumn(&dropColumnUser{}, "Note") {
+		t.Error("dropColumn() 没有删除列")
+	}
+	if after := indexes(t, db, "drop_column_users"); !reflect.DeepEqual(after, before) {
+		t.Errorf("indexes = %v, want %v", after, before)
+	}
+}
+
+// schemaIndexes 返回全部表的索引，格式为 表名.索引名
+func schemaIndexes(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var names []string
+	err := db.Raw("SELECT tbl_name || '.' || name FROM sqlite_master WHERE type = 'index' ORDER BY tbl_name, name").
+		Scan(&names).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+// TestDownUpKeepsIndexes 回滚任意步数再重新执行，索引应与一次性执行全部迁移时相同
+func TestDownUpKeepsIndexes(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	want := schemaIndexes(t, db)
+	for steps := 1; steps <= len(All()); steps++ {
+		rolledBack, err := Down(db, steps)
+		if err != nil {
+			t.Fatalf("Down(%d) error = %v", steps, err)
+		}
+		if _, err := Up(db); err != nil {
+			t.Fatalf("Up() after Down(%d) error = %v", steps, err)
+		}
+		if got := schemaIndexes(t, db); !reflect.DeepEqual(got, want) {
+			t.Errorf("回滚到 %s 之前再执行后 indexes = %v, want %v", rolledBack[len(rolledBack)-1].Version, got, want)
+		}
+	}
+}
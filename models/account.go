@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// AccountExport 个人数据导出，对应归档中的 data.json
+type AccountExport struct {
+	ExportedAt  time.Time          `json:"exportedAt"`
+	Profile     ExportProfile      `json:"profile"`
+	Articles    []ExportArticle    `json:"articles"`
+	Comments    []ExportComment    `json:"comments"`
+	Favorites   []ExportArticleRef `json:"favorites"`
+	Following   []string           `json:"following"`
+	Followers   []string           `json:"followers"`
+	Sessions    []SessionView      `json:"sessions"`
+	APIKeys     []APIKeyView       `json:"apiKeys"`
+	Identities  []IdentityView     `json:"identities"`
+	Invitations []InvitationView   `json:"invitations"`
+}
+
+type ExportProfile struct {
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	Bio                 string     `json:"bio"`
+	Image               string     `json:"image"`
+	Role                string     `json:"role"`
+	EmailVerified       bool       `json:"emailVerified"`
+	TwoFactorEnabled    bool       `json:"twoFactorEnabled"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt"`
+	InvitedBy           string     `json:"invitedBy,omitempty"`
+}
+
+type ExportArticle struct {
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Body           string     `json:"body"`
+	TagList        []string   `json:"tagList"`
+	FavoritesCount int        `json:"favoritesCount"`
+	Status         string     `json:"status"`
+	PublishedAt    *time.Time `json:"publishedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+type ExportComment struct {
+	ID           uint      `json:"id"`
+	ArticleSlug  string    `json:"articleSlug"`
+	ArticleTitle string    `json:"articleTitle"`
+	Body         string    `json:"body"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type ExportArticleRef struct {
+	Slug        string    `json:"slug"`
+	Title       string    `json:"title"`
+	FavoritedAt time.Time `json:"favoritedAt"`
+}
+
+// AccountDeletionResponse 申请注销后返回清理时间，在此之前可以撤销
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletionScheduledAt"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// APIKey 用户创建的个人 API 密钥，只存哈希，原文仅在创建时返回一次
+type APIKey struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;index" json:"-"`
+	Name       string     `gorm:"size:100;not null" json:"-"`
+	Prefix     string     `gorm:"size:16;not null" json:"-"` // 密钥开头几位，便于用户辨认
+	KeyHash    string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"size:255;not null" json:"-"` // 逗号分隔的授权范围
+	ExpiresAt  *time.Time `json:"-"`                          // 为空表示永不过期
+	LastUsedAt *time.Time `json:"-"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+// ScopeList 授权范围列表
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// Active 密钥未吊销且未过期
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// APIKeyRequest 创建 API 密钥，expiresAt 为空时使用默认有效期
+type APIKeyRequest struct {
+	APIKey struct {
+		Name      string     `json:"name" binding:"required,max=100"`
+		Scopes    []string   `json:"scopes" binding:"required,min=1"`
+		ExpiresAt *time.Time `json:"expiresAt"`
+	} `json:"apiKey"`
+}
+
+type APIKeyView struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
+
+// APIKeyResponse 创建成功时 key 为密钥原文，之后无法再次查看
+type APIKeyResponse struct {
+	APIKey APIKeyView `json:"apiKey"`
+	Key    string     `json:"key,omitempty"`
+}
+
+type APIKeysResponse struct {
+	APIKeys []APIKeyView `json:"apiKeys"`
+}
+
+// View 转换为响应结构
+func (k *APIKey) View() APIKeyView {
+	return APIKeyView{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.ScopeList(),
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
@@ -5,8 +5,26 @@ import (
 	"encoding/json"
 	"errors"
 	"gorm.io/gorm"
+	"time"
 )
 
+// 文章状态
+const (
+	// ArticleDraft 草稿，只有作者可以查看
+	ArticleDraft = "draft"
+	// ArticlePublished 已发布，出现在文章列表和关注动态中
+	ArticlePublished = "published"
+	// ArticleArchived 已归档，可以通过链接查看，不再出现在列表中，也不能评论和收藏
+	ArticleArchived = "archived"
+	// ArticleUnlisted 不公开列出，只能通过链接查看
+	ArticleUnlisted = "unlisted"
+	// ArticleScheduled 定时发布，到 PublishAt 之前与草稿一样只有作者可以查看
+	ArticleScheduled = "scheduled"
+)
+
+// ArticlePublishedEvent 文章发布时推送的事件类型
+const ArticlePublishedEvent = "article.published"
+
 type TagList []string
 
 // Value 将 TagList 转换为 JSON 字节切片
@@ -15,25 +33,36 @@ func (t TagList) Value() (driver.Value, error) {
 }
 
 // Scan 将 JSON 字节切片转换为TagList
+// 不同数据库驱动返回的 JSON 列类型不同，MySQL 为 []byte，SQLite 和 PostgreSQL 可能为 string
 func (t *TagList) Scan(value interface{}) error {
-	b, ok := value.([]byte)
-	if !ok {
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, &t)
+	case string:
+		return json.Unmarshal([]byte(v), &t)
+	default:
 		return errors.New("type assertion to []byte failed")
 	}
-	return json.Unmarshal(b, &t)
 }
 
 type Article struct {
 	gorm.Model
-	Slug           string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"slug"`
-	Title          string    `gorm:"not null" json:"title"`
-	Description    string    `json:"description"`
-	Body           string    `gorm:"not null" json:"body"`
-	TagList        TagList   `gorm:"type:json" json:"tagList"`
-	Favorited      bool      `json:"favorited"`
-	FavoritesCount int       `json:"favoritesCount"`
-	AuthorID       uint      `gorm:"not null" json:"-"`
-	Author         UserModel `gorm:"foreignKey:AuthorID" json:"author"`
+	Slug           string     `gorm:"type:varchar(255);uniqueIndex;not null" json:"slug"`
+	SlugPinned     bool       `gorm:"not null;default:false" json:"slugPinned"` // 自定义的 slug，修改标题时不随标题变化
+	Title          string     `gorm:"not null" json:"title"`
+	Description    string     `json:"description"`
+	Body           string     `gorm:"not null" json:"body"`
+	TagList        TagList    `gorm:"type:json" json:"tagList"`
+	Favorited      bool       `json:"favorited"`
+	FavoritesCount int        `json:"favoritesCount"`
+	Status         string     `gorm:"type:varchar(16);not null;default:published;index" json:"status"`
+	PublishedAt    *time.Time `gorm:"index" json:"publishedAt"`
+	PublishAt      *time.Time `gorm:"index" json:"publishAt"`
+	AuthorID       uint       `gorm:"not null" json:"-"`
+	Author         UserModel  `gorm:"foreignKey:AuthorID" json:"author"`
 }
 
 type ArticleListResponse struct {
@@ -51,13 +80,34 @@ type CreateArticleRequest struct {
 		Description string   `json:"description" binding:"required"`
 		Body        string   `json:"body" binding:"required"`
 		TagList     []string `json:"tagList"`
+		// Status 为空时直接发布，填 draft 保存为草稿
+		Status string `json:"status" binding:"omitempty,oneof=draft published unlisted"`
+		// PublishAt 定时发布时间，不能与 Status 同时指定
+		PublishAt *time.Time `json:"publishAt"`
+		// Slug 自定义 slug，为空时根据标题生成
+		Slug string `json:"slug" binding:"max=255"`
 	} `json:"article" binding:"required"`
 }
 
 type UpdateArticleRequest struct {
 	Article struct {
-		Title       *string `json:"title"`
-		Description *string `json:"description"`
-		Body        *string `json:"body"`
+		Title       *string    `json:"title"`
+		Description *string    `json:"description"`
+		Body        *string    `json:"body"`
+		Status      *string    `json:"status" binding:"omitempty,oneof=draft published archived unlisted"`
+		PublishAt   *time.Time `json:"publishAt"`
+		// Slug 自定义 slug 并固定下来；传空字符串取消固定，之后修改标题时重新随标题生成
+		Slug *string `json:"slug" binding:"omitempty,max=255"`
 	} `json:"article"`
 }
+
+// ArticlePublishedPayload article.published 事件的内容
+type ArticlePublishedPayload struct {
+	Slug        string    `json:"slug"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	TagList     []string  `json:"tagList"`
+	Author      string    `json:"author"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
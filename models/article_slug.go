@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ArticleSlug 文章用过的旧 slug，slug 变化时追加，旧链接通过它找到文章的当前 slug。
+// 旧 slug 一直归原文章所有，不会分配给其他文章
+type ArticleSlug struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"not null"`
+	Slug      string    `gorm:"type:varchar(255);uniqueIndex;not null"`
+	ArticleID uint      `gorm:"not null;index"`
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 审计日志的操作类型
+const (
+	AuditImpersonationStart = "impersonation.start"
+	AuditImpersonationEnd   = "impersonation.end"
+	// AuditImpersonatedRequest 代入期间的写请求
+	AuditImpersonatedRequest = "impersonation.request"
+)
+
+// Impersonation 管理员代入用户身份的记录，TokenID 为代入令牌的 jti，提前结束时据此吊销
+// 用户名按发起时记录，之后用户改名或注销也不影响追溯
+type Impersonation struct {
+	gorm.Model
+	ActorID         uint       `gorm:"not null;index" json:"-"`
+	ActorUsername   string     `gorm:"size:255;not null" json:"-"`
+	SubjectID       uint       `gorm:"not null;index" json:"-"`
+	SubjectUsername string     `gorm:"size:255;not null" json:"-"`
+	Reason          string     `gorm:"size:255;not null" json:"-"`
+	TokenID         string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt       time.Time  `gorm:"not null" json:"-"`
+	EndedAt         *time.Time `json:"-"`
+}
+
+// Active 尚未提前结束且未过期
+func (i *Impersonation) Active(now time.Time) bool {
+	return i.EndedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// AuditLog 审计日志，只追加不修改
+type AuditLog struct {
+	ID              uint      `gorm:"primarykey"`
+	CreatedAt       time.Time `gorm:"index"`
+	Action          string    `gorm:"size:32;not null;index"`
+	ActorID         uint      `gorm:"not null;index"`
+	ActorUsername   string    `gorm:"size:255;not null"`
+	SubjectID       uint      `gorm:"index"`
+	SubjectUsername string    `gorm:"size:255"`
+	ImpersonationID *uint     `gorm:"index"`
+	Method          string    `gorm:"size:16"`
+	Path            string    `gorm:"size:255"`
+	Status          int
+	IP              string `gorm:"size:64"`
+	Detail          string `gorm:"size:255"` // 代入原因等补充信息
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"` // 代入原因，例如工单编号
+}
+
+type ImpersonationView struct {
+	ID        uint       `json:"id"`
+	Actor     string     `json:"actor"`
+	Subject   string     `json:"subject"`
+	Reason    string     `json:"reason"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	EndedAt   *time.Time `json:"endedAt"`
+	Active    bool       `json:"active"`
+}
+
+// ImpersonationResponse 发起代入时 token 为代入令牌，用 "Authorization: Bearer <token>" 以被代入用户的身份访问
+type ImpersonationResponse struct {
+	Impersonation ImpersonationView `json:"impersonation"`
+	Token         string            `json:"token,omitempty"`
+}
+
+type ImpersonationsResponse struct {
+	Impersonations []ImpersonationView `json:"impersonations"`
+}
+
+// View 转换为响应结构
+func (i *Impersonation) View(now time.Time) ImpersonationView {
+	return ImpersonationView{
+		ID:        i.ID,
+		Actor:     i.ActorUsername,
+		Subject:   i.SubjectUsername,
+		Reason:    i.Reason,
+		CreatedAt: i.CreatedAt,
+		ExpiresAt: i.ExpiresAt,
+		EndedAt:   i.EndedAt,
+		Active:    i.Active(now),
+	}
+}
+
+type AuditLogView struct {
+	ID              uint      `json:"id"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Action          string    `json:"action"`
+	Actor           string    `json:"actor"`
+	Subject         string    `json:"subject,omitempty"`
+	ImpersonationID *uint     `json:"impersonationId,omitempty"`
+	Method          string    `json:"method,omitempty"`
+	Path            string    `json:"path,omitempty"`
+	Status          int       `json:"status,omitempty"`
+	IP              string    `json:"ip,omitempty"`
+	Detail          string    `json:"detail,omitempty"`
+}
+
+type AuditLogResponse struct {
+	Entries    []AuditLogView `json:"entries"`
+	EntryCount int64          `json:"entryCount"`
+}
+
+// View 转换为响应结构
+func (l *AuditLog) View() AuditLogView {
+	return AuditLogView{
+		ID:              l.ID,
+		CreatedAt:       l.CreatedAt,
+		Action:          l.Action,
+		Actor:           l.ActorUsername,
+		Subject:         l.SubjectUsername,
+		ImpersonationID: l.ImpersonationID,
+		Method:          l.Method,
+		Path:            l.Path,
+		Status:          l.Status,
+		IP:              l.IP,
+		Detail:          l.Detail,
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// UserIdentity 关联到用户的第三方身份，同一提供方的同一 subject 只能关联一个用户
+type UserIdentity struct {
+	gorm.Model
+	UserID      uint       `gorm:"not null;index" json:"-"`
+	Provider    string     `gorm:"size:64;not null;uniqueIndex:idx_identity_provider_subject" json:"-"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject" json:"-"`
+	Email       string     `gorm:"size:255" json:"-"` // 最近一次登录时提供方返回的邮箱，仅供展示
+	LastLoginAt *time.Time `json:"-"`
+}
+
+// OIDCLoginState 跳转到身份提供方之前保存的登录请求，回调时凭 state 取回并作废
+type OIDCLoginState struct {
+	gorm.Model
+	StateHash    string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Provider     string     `gorm:"size:64;not null" json:"-"`
+	CodeVerifier string     `gorm:"size:128;not null" json:"-"`
+	Nonce        string     `gorm:"size:64;not null" json:"-"`
+	LinkUserID   *uint      `json:"-"` // 已登录用户发起时为关联身份，否则为登录
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"-"`
+	UsedAt       *time.Time `json:"-"`
+}
+
+// TableName 默认命名会把 OIDC 拆成 o_id_c
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
+
+type OIDCProviderView struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type OIDCProvidersResponse struct {
+	Providers []OIDCProviderView `json:"providers"`
+}
+
+// OIDCAuthorizeResponse 前端跳转到 authorizationUrl，身份提供方回调前端后再调用 callback 接口
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+}
+
+// OIDCCallbackRequest 身份提供方回调地址上的 code 和 state 参数
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+type IdentityView struct {
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt"`
+}
+
+type IdentitiesResponse struct {
+	Identities []IdentityView `json:"identities"`
+}
+
+// View 转换为响应结构
+func (i *UserIdentity) View() IdentityView {
+	return IdentityView{Provider: i.Provider, Email: i.Email, CreatedAt: i.CreatedAt, LastLoginAt: i.LastLoginAt}
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Invitation 邀请码，只存哈希，原文仅在创建时返回一次
+type Invitation struct {
+	gorm.Model
+	InviterID *uint `gorm:"index" json:"-"` // 为空表示通过 invite 子命令创建
+
+	CodeHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Prefix    string     `gorm:"size:16;not null" json:"-"` // 邀请码开头几位，便于辨认
+	Note      string     `gorm:"size:255" json:"-"`
+	MaxUses   int        `gorm:"not null;default:1" json:"-"`
+	UseCount  int        `gorm:"not null;default:0" json:"-"`
+	ExpiresAt *time.Time `json:"-"` // 为空表示永不过期
+	RevokedAt *time.Time `json:"-"`
+	Inviter   *UserModel `gorm:"foreignKey:InviterID" json:"-"`
+	// Invitees 使用该邀请码注册的用户
+	Invitees []UserModel `gorm:"foreignKey:InvitationID" json:"-"`
+}
+
+// Active 未吊销、未过期且还有剩余次数
+func (i *Invitation) Active(now time.Time) bool {
+	return i.RevokedAt == nil && (i.ExpiresAt == nil || now.Before(*i.ExpiresAt)) && i.UseCount < i.MaxUses
+}
+
+// InvitationRequest 创建邀请码，maxUses 为空时为 1，expiresAt 为空时使用默认有效期
+type InvitationRequest struct {
+	Invitation struct {
+		MaxUses   int        `json:"maxUses" binding:"omitempty,min=1,max=10000"`
+		ExpiresAt *time.Time `json:"expiresAt"`
+		Note      string     `json:"note" binding:"max=255"`
+	} `json:"invitation"`
+}
+
+type InvitationView struct {
+	ID        uint       `json:"id"`
+	Prefix    string     `json:"prefix"`
+	Note      string     `json:"note"`
+	Inviter   string     `json:"inviter"` // 通过命令行创建时为空
+	MaxUses   int        `json:"maxUses"`
+	UseCount  int        `json:"useCount"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	Active    bool       `json:"active"`
+	Invitees  []string   `json:"invitees"` // 使用该邀请码注册的用户名
+}
+
+// InvitationResponse 创建成功时 code 为邀请码原文，之后无法再次查看
+type InvitationResponse struct {
+	Invitation InvitationView `json:"invitation"`
+	Code       string         `json:"code,omitempty"`
+}
+
+type InvitationsResponse struct {
+	Invitations      []InvitationView `json:"invitations"`
+	InvitationsCount int64            `json:"invitationsCount"`
+}
+
+// RegistrationResponse 当前的注册方式，前端据此决定是否显示邀请码输入框
+type RegistrationResponse struct {
+	Mode               string `json:"mode"`
+	InvitationRequired bool   `json:"invitationRequired"`
+}
+
+// View 转换为响应结构，需要预加载 Inviter 和 Invitees
+func (i *Invitation) View(now time.Time) InvitationView {
+	view := InvitationView{
+		ID:        i.ID,
+		Prefix:    i.Prefix,
+		Note:      i.Note,
+		MaxUses:   i.MaxUses,
+		UseCount:  i.UseCount,
+		CreatedAt: i.CreatedAt,
+		ExpiresAt: i.ExpiresAt,
+		RevokedAt: i.RevokedAt,
+		Active:    i.Active(now),
+		Invitees:  []string{},
+	}
+	if i.Inviter != nil {
+		view.Inviter = i.Inviter.Username
+	}
+	for _, invitee := range i.Invitees {
+		view.Invitees = append(view.Invitees, invitee.Username)
+	}
+	return view
+}
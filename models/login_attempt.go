@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// LoginAttempt 某个账号或 IP 的连续登录失败次数，用于多实例共享的登录限制
+type LoginAttempt struct {
+	ID            uint      `gorm:"primarykey"`
+	AttemptKey    string    `gorm:"size:191;not null;uniqueIndex"`
+	Failures      int       `gorm:"not null"`
+	LastFailureAt time.Time `gorm:"not null;index"`
+}
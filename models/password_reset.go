@@ -0,0 +1,31 @@
+package models
+
+import (
+	"goDemo/passwords"
+	"gorm.io/gorm"
+	"time"
+)
+
+// PasswordResetToken 密码重置令牌，只存哈希，使用一次后失效
+type PasswordResetToken struct {
+	gorm.Model
+	UserID    uint       `gorm:"not null;index" json:"-"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"-"`
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// PasswordPolicyErrorResponse 新密码不符合策略时的响应，violations 中的 code 供客户端识别具体规则
+type PasswordPolicyErrorResponse struct {
+	Errors     map[string][]string   `json:"errors"`
+	Violations []passwords.Violation `json:"violations"`
+}
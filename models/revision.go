@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// ArticleRevision 文章的一个版本，创建文章和每次修改内容时各追加一条，只追加不修改
+// Number 在同一篇文章内从 1 开始递增，最新的版本与文章当前内容一致
+type ArticleRevision struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"not null"`
+	ArticleID uint      `gorm:"not null;uniqueIndex:idx_article_revisions_number"`
+	Number    int       `gorm:"not null;uniqueIndex:idx_article_revisions_number"`
+	// EditorID 修改人，管理员编辑他人文章时与作者不同
+	EditorID    uint      `gorm:"not null;index"`
+	Editor      UserModel `gorm:"foreignKey:EditorID"`
+	Title       string    `gorm:"not null"`
+	Description string
+	Body        string  `gorm:"not null"`
+	TagList     TagList `gorm:"type:json"`
+	// RestoredFrom 由哪个版本恢复而来，普通修改为空
+	RestoredFrom *int
+}
+
+type RevisionView struct {
+	Number       int       `json:"number"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Editor       string    `json:"editor"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Body         string    `json:"body"`
+	TagList      []string  `json:"tagList"`
+	RestoredFrom *int      `json:"restoredFrom"`
+}
+
+type RevisionResponse struct {
+	Revision RevisionView `json:"revision"`
+}
+
+type RevisionsResponse struct {
+	Revisions      []RevisionView `json:"revisions"`
+	RevisionsCount int            `json:"revisionsCount"`
+}
+
+// View 转换为响应结构，修改人的账号已删除时 Editor 为空
+func (r *ArticleRevision) View() RevisionView {
+	return RevisionView{
+		Number:       r.Number,
+		CreatedAt:    r.CreatedAt,
+		Editor:       r.Editor.Username,
+		Title:        r.Title,
+		Description:  r.Description,
+		Body:         r.Body,
+		TagList:      r.TagList,
+		RestoredFrom: r.RestoredFrom,
+	}
+}
+
+// 差异行的类型
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine 逐行差异中的一行，OldLine、NewLine 为在旧版本、新版本中的行号（从 1 开始），不存在时为 0
+type DiffLine struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
+
+// RevisionDiff 两个版本之间各字段的逐行差异，标签每个一行参与比较
+type RevisionDiff struct {
+	From        int        `json:"from"`
+	To          int        `json:"to"`
+	Title       []DiffLine `json:"title"`
+	Description []DiffLine `json:"description"`
+	Body        []DiffLine `json:"body"`
+	TagList     []DiffLine `json:"tagList"`
+}
+
+type RevisionDiffResponse struct {
+	Diff RevisionDiff `json:"diff"`
+}
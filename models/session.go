@@ -0,0 +1,44 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Session 一次登录产生的会话，与同一次登录轮换出来的刷新令牌家族一一对应
+// 会话被吊销后，它签发的访问令牌和刷新令牌立即失效
+type Session struct {
+	gorm.Model
+	UserID       uint       `gorm:"not null;index" json:"-"`
+	FamilyID     string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UserAgent    string     `gorm:"size:255" json:"-"`
+	IP           string     `gorm:"size:64" json:"-"`
+	LastActiveAt time.Time  `gorm:"not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"-"` // 最新一个刷新令牌的过期时间，之后会话自然结束
+	RevokedAt    *time.Time `json:"-"`
+}
+
+type SessionView struct {
+	ID           uint      `json:"id"`
+	UserAgent    string    `json:"userAgent"`
+	IP           string    `json:"ip"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastActiveAt time.Time `json:"lastActiveAt"`
+	Current      bool      `json:"current"` // 是否为发起本次请求的会话
+}
+
+type SessionsResponse struct {
+	Sessions []SessionView `json:"sessions"`
+}
+
+// View 转换为响应结构，currentFamilyID 为当前请求所属的令牌家族
+func (s *Session) View(currentFamilyID string) SessionView {
+	return SessionView{
+		ID:           s.ID,
+		UserAgent:    s.UserAgent,
+		IP:           s.IP,
+		CreatedAt:    s.CreatedAt,
+		LastActiveAt: s.LastActiveAt,
+		Current:      s.FamilyID == currentFamilyID,
+	}
+}
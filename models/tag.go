@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Tag 规范化后的标签，名称唯一。文章的 TagList 保留标签的顺序用于展示，ArticleTag 用于按标签查询和统计
+type Tag struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"not null"`
+	Name      string    `gorm:"type:varchar(64);uniqueIndex;not null"`
+}
+
+// ArticleTag 文章与标签的多对多关联，与文章的 TagList 在同一事务中更新
+type ArticleTag struct {
+	ArticleID uint `gorm:"primaryKey;autoIncrement:false"`
+	TagID     uint `gorm:"primaryKey;autoIncrement:false;index"`
+}
+
+// TagCount 标签及使用它的已发布文章数量
+type TagCount struct {
+	Tag           string `json:"tag"`
+	ArticlesCount int    `json:"articlesCount"`
+}
+
+// TagsResponse Tags 按热度排序，与 TagCounts 一一对应
+type TagsResponse struct {
+	Tags      []string   `json:"tags"`
+	TagCounts []TagCount `json:"tagCounts"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// RefreshToken 服务端保存的刷新令牌，只存哈希
+// 同一次登录后轮换出来的令牌属于同一个家族（FamilyID）
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint       `gorm:"not null;index" json:"-"`
+	FamilyID  string     `gorm:"size:64;not null;index" json:"-"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"-"` // 已轮换，再次使用视为泄露
+	RevokedAt *time.Time `json:"-"`
+}
+
+// RevokedToken 已吊销但尚未过期的访问令牌
+type RevokedToken struct {
+	gorm.Model
+	JTI       string    `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"-"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// RecoveryCode 两步验证的恢复码，只存哈希，每个只能使用一次
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint       `gorm:"not null;index" json:"-"`
+	CodeHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UsedAt   *time.Time `json:"-"`
+}
+
+// TwoFactorEnrollResponse 开始绑定认证器时返回的密钥，可手动输入或通过 otpauth 地址扫码
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse 恢复码只在生成时返回一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorChallengeResponse 密码正确但需要两步验证时，登录接口返回挑战令牌而不是访问令牌
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+}
+
+// TwoFactorLoginRequest 用挑战令牌和验证码（或恢复码）换取访问令牌
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
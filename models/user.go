@@ -1,6 +1,9 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type UserModel struct {
 	gorm.Model
@@ -9,10 +12,47 @@ type UserModel struct {
 	Password string `gorm:"text;not null;column:password" json:"-"`
 	Bio      string `gorm:"text" json:"bio"`
 	Image    string `gorm:"size:255" json:"image"`
+	Role     string `gorm:"size:32;not null;default:user" json:"-"`
+	// EmailVerifiedAt 为空表示邮箱尚未验证
+	EmailVerifiedAt    *time.Time `json:"-"`
+	VerificationSentAt *time.Time `json:"-"` // 最近一次发送验证邮件的时间，用于限制重发频率
+	// 两步验证，TOTPSecret 不为空但 TOTPEnabledAt 为空表示已开始绑定、尚未确认
+	TOTPSecret      string     `gorm:"size:64" json:"-"`
+	TOTPEnabledAt   *time.Time `json:"-"`
+	TOTPLastCounter int64      `gorm:"not null;default:0" json:"-"` // 最近一次使用的时间窗口，防止验证码重放
+	// DeletionScheduledAt 申请注销后到期清理的时间，为空表示未申请
+	DeletionScheduledAt *time.Time `gorm:"index" json:"-"`
+	// 通过邀请码注册时记录邀请人和所用的邀请码
+	InvitedByID  *uint `gorm:"index" json:"-"`
+	InvitationID *uint `gorm:"index" json:"-"`
 }
 
+// TwoFactorEnabled 是否已开启两步验证
+func (u *UserModel) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// HasPassword 是否设置了密码，通过第三方登录创建的账号没有密码
+func (u *UserModel) HasPassword() bool {
+	return u.Password != ""
+}
+
+// EmailVerified 邮箱是否已验证
+func (u *UserModel) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// RegisterRequest 注册请求，UserModel 的密码字段不参与 JSON 序列化，不能直接用于绑定
 type RegisterRequest struct {
-	User UserModel `json:"user"`
+	User struct {
+		Username string `json:"username" binding:"required"`
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+		Bio      string `json:"bio"`
+		Image    string `json:"image"`
+		// InvitationCode 邀请码，registration.mode 为 invite_only 时必填
+		InvitationCode string `json:"invitationCode"`
+	} `json:"user"`
 }
 
 type UserLoginRequest struct {
@@ -24,14 +64,27 @@ type UserLoginRequest struct {
 
 type UserResponse struct {
 	User struct {
-		Email    string `json:"email"`
-		Token    string `json:"token"`
-		Username string `json:"username"`
-		Bio      string `json:"bio"`
-		Image    string `json:"image"`
+		Email         string `json:"email"`
+		Token         string `json:"token"`
+		RefreshToken  string `json:"refreshToken,omitempty"`
+		Username      string `json:"username"`
+		Bio           string `json:"bio"`
+		Image         string `json:"image"`
+		Role          string `json:"role"`
+		EmailVerified bool   `json:"emailVerified"`
+		// DeletionScheduledAt 已申请注销时返回到期时间，提醒用户可以撤销
+		DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+		// Impersonation 管理员代入该用户身份时返回，前端据此显示提示
+		Impersonation *ImpersonationFlag `json:"impersonation,omitempty"`
 	} `json:"user"`
 }
 
+// ImpersonationFlag 当前请求是管理员代入的
+type ImpersonationFlag struct {
+	ImpersonatedBy string    `json:"impersonatedBy"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
 type UserUpdateRequest struct {
 	User struct {
 		Email    *string `json:"email,omitempty"`
@@ -41,3 +94,23 @@ type UserUpdateRequest struct {
 		Image    *string `json:"image,omitempty"`
 	} `json:"user"`
 }
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UserRoleResponse 管理后台展示的用户角色信息
+type UserRoleResponse struct {
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type UserRolesResponse struct {
+	Users []UserRoleResponse `json:"users"`
+}
@@ -0,0 +1,186 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"goDemo/utils"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// keyRefreshInterval 遇到未知 kid 时重新拉取 JWKS 的最短间隔，防止被伪造的 kid 放大请求
+const keyRefreshInterval = time.Minute
+
+// Claims ID Token 中用到的用户信息
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Nickname          string
+	Picture           string
+}
+
+// VerifyIDToken 校验 ID Token 的签名、签发方、受众、有效期和 nonce，返回其中的用户信息
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*Claims, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mapClaims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.get(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(md.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w：%v", ErrInvalidIDToken, err)
+	}
+	// 受众不止一个时，azp 必须是本客户端
+	if aud, _ := mapClaims.GetAudience(); len(aud) > 1 && mapClaims["azp"] != p.ClientID {
+		return nil, fmt.Errorf("%w：azp 不匹配", ErrInvalidIDToken)
+	}
+	if got, _ := mapClaims["nonce"].(string); got == "" || got != nonce {
+		return nil, fmt.Errorf("%w：nonce 不匹配", ErrInvalidIDToken)
+	}
+	claims := &Claims{}
+	claims.Subject, _ = mapClaims.GetSubject()
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w：缺少 sub", ErrInvalidIDToken)
+	}
+	claims.Email, _ = mapClaims["email"].(string)
+	// 个别提供方把 email_verified 编码为字符串
+	switch v := mapClaims["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = v
+	case string:
+		claims.EmailVerified = v == "true"
+	}
+	claims.Name, _ = mapClaims["name"].(string)
+	claims.PreferredUsername, _ = mapClaims["preferred_username"].(string)
+	claims.Nickname, _ = mapClaims["nickname"].(string)
+	claims.Picture, _ = mapClaims["picture"].(string)
+	return claims, nil
+}
+
+// keySet 缓存身份提供方的签名公钥，遇到未知 kid 时重新拉取以支持对方轮换密钥
+type keySet struct {
+	uri       string
+	provider  *Provider
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+func (s *keySet) get(ctx context.Context, kid string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+	if time.Since(s.fetchedAt) < keyRefreshInterval {
+		return nil, fmt.Errorf("未知的签名密钥：%s", kid)
+	}
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("未知的签名密钥：%s", kid)
+}
+
+// lookup 未携带 kid 时，只有一把密钥才能确定使用哪一把
+func (s *keySet) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *keySet) refresh(ctx context.Context) error {
+	s.fetchedAt = time.Now()
+	var set utils.JWKSet
+	if err := s.provider.getJSON(ctx, s.uri, &set); err != nil {
+		return fmt.Errorf("获取签名公钥失败：%w", err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := publicKey(jwk)
+		if err != nil {
+			continue // 忽略不支持的密钥类型，不影响其他密钥
+		}
+		keys[jwk.Kid] = key
+	}
+	s.keys = keys
+	return nil
+}
+
+// publicKey 把 JWK 转换为 jwt 库使用的公钥
+func publicKey(jwk utils.JWK) (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("不支持的曲线：%s", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("不支持的曲线：%s", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("无效的 Ed25519 公钥")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("不支持的密钥类型：%s", jwk.Kty)
+}
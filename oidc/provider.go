@@ -0,0 +1,213 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goDemo/config"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrExchangeFailed 授权码无效、已使用或与 PKCE 校验值不匹配
+	ErrExchangeFailed = errors.New("授权码换取令牌失败")
+	// ErrInvalidIDToken ID Token 签名、签发方、受众、有效期或 nonce 校验失败
+	ErrInvalidIDToken = errors.New("ID Token 校验失败")
+	// ErrProviderUnavailable 无法访问身份提供方，或其配置、响应不符合规范
+	ErrProviderUnavailable = errors.New("身份提供方暂时不可用")
+)
+
+// defaultScopes 未配置 scopes 时请求的范围
+var defaultScopes = []string{"openid", "email", "profile"}
+
+// Provider 一个 OpenID Connect 身份提供方，使用授权码 + PKCE 流程
+// 端点和签名公钥在首次使用时从 issuer 自动发现并缓存
+type Provider struct {
+	Name              string
+	DisplayName       string
+	Issuer            string
+	ClientID          string
+	ClientSecret      string
+	Scopes            []string
+	RedirectURL       string
+	LinkVerifiedEmail bool
+	HTTPClient        *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     *keySet
+}
+
+// metadata /.well-known/openid-configuration 中用到的字段
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// New 根据配置创建身份提供方，publicURL 用于拼接默认回调地址
+func New(cfg config.OIDCProviderConfig, publicURL string) *Provider {
+	p := &Provider{
+		Name:              cfg.Name,
+		DisplayName:       cfg.DisplayName,
+		Issuer:            strings.TrimSuffix(cfg.Issuer, "/"),
+		ClientID:          cfg.ClientID,
+		ClientSecret:      cfg.ClientSecret.Value(),
+		Scopes:            cfg.Scopes,
+		RedirectURL:       cfg.RedirectURL,
+		LinkVerifiedEmail: cfg.LinkVerifiedEmail,
+		HTTPClient:        &http.Client{Timeout: 10 * time.Second},
+	}
+	if p.DisplayName == "" {
+		p.DisplayName = p.Name
+	}
+	if len(p.Scopes) == 0 {
+		p.Scopes = defaultScopes
+	}
+	if p.RedirectURL == "" {
+		p.RedirectURL = strings.TrimSuffix(publicURL, "/") + "/oidc/" + p.Name + "/callback"
+	}
+	return p
+}
+
+// NewProviders 创建配置中的全部身份提供方，按名称索引
+func NewProviders(cfg config.OIDCConfig, publicURL string) map[string]*Provider {
+	providers := make(map[string]*Provider, len(cfg.Providers))
+	for _, pc := range cfg.Providers {
+		providers[pc.Name] = New(pc, publicURL)
+	}
+	return providers
+}
+
+// Sorted 按名称排序，便于稳定地展示登录方式
+func Sorted(providers map[string]*Provider) []*Provider {
+	list := make([]*Provider, 0, len(providers))
+	for _, p := range providers {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// AuthCodeURL 拼接跳转到身份提供方的授权地址
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(md.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return md.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange 用授权码和 PKCE 校验值换取 ID Token 原文
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"client_id":     {p.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w：%v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("%w：解析令牌响应失败：%v", ErrProviderUnavailable, err)
+	}
+	// 4xx 通常是授权码无效或已被使用，属于调用方的问题；5xx 说明身份提供方不可用
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return "", fmt.Errorf("%w：%s %s", ErrExchangeFailed, token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w：令牌端点返回 %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+	if token.IDToken == "" {
+		return "", fmt.Errorf("%w：响应中没有 id_token", ErrExchangeFailed)
+	}
+	return token.IDToken, nil
+}
+
+// discover 读取并缓存提供方元数据，失败时下次调用重试
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+	var md metadata
+	if err := p.getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", &md); err != nil {
+		return nil, fmt.Errorf("%w：获取 %s 的 OpenID 配置失败：%v", ErrProviderUnavailable, p.Name, err)
+	}
+	// 规范要求元数据中的 issuer 与请求地址一致，防止被其他提供方冒充
+	if strings.TrimSuffix(md.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("%w：%s 的 issuer 不匹配：%s", ErrProviderUnavailable, p.Name, md.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, fmt.Errorf("%w：%s 的 OpenID 配置缺少必要的端点", ErrProviderUnavailable, p.Name)
+	}
+	p.metadata = &md
+	p.keys = &keySet{uri: md.JWKSURI, provider: p}
+	return p.metadata, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 返回 %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
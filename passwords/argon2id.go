@@ -0,0 +1,89 @@
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id 使用 PHC 字符串格式：$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type Argon2id struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  int
+	KeyLength   uint32
+}
+
+// argon2Params 从编码哈希中解析出的参数
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, a.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a *Argon2id) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a *Argon2id) Verify(password string, encoded string) (bool, error) {
+	params, err := parseArgon2(encoded)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+	return subtle.ConstantTimeCompare(key, params.key) == 1, nil
+}
+
+func (a *Argon2id) Outdated(encoded string) bool {
+	params, err := parseArgon2(encoded)
+	if err != nil {
+		return true
+	}
+	return params.memory != a.Memory || params.iterations != a.Iterations ||
+		params.parallelism != a.Parallelism || uint32(len(params.key)) != a.KeyLength
+}
+
+func (a *Argon2id) MaxBytes() int {
+	return 0
+}
+
+func parseArgon2(encoded string) (*argon2Params, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("不支持的 argon2 版本：%s", parts[2])
+	}
+	p := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return nil, fmt.Errorf("argon2 参数无效：%w", err)
+	}
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("argon2 盐值无效：%w", err)
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, fmt.Errorf("argon2 哈希无效：%w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,44 @@
+package passwords
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Bcrypt 兼容早期注册用户的哈希，新密码默认不再使用
+type Bcrypt struct {
+	Cost int
+}
+
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (b *Bcrypt) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// Verify 超过 72 个字节的密码不可能与 bcrypt 哈希匹配，按密码错误处理，由调用方计入失败次数
+func (b *Bcrypt) Verify(password string, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) || errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *Bcrypt) Outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.Cost
+}
+
+// MaxBytes bcrypt 只处理前 72 个字节，更长的密码会被拒绝
+func (b *Bcrypt) MaxBytes() int {
+	return 72
+}
@@ -0,0 +1,57 @@
+package passwords
+
+import (
+	"goDemo/config"
+	"strings"
+	"testing"
+)
+
+func TestBcryptVerify(t *testing.T) {
+	b := &Bcrypt{Cost: 4}
+	encoded, err := b.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"correct", "correct horse battery staple", true},
+		{"wrong", "incorrect horse", false},
+		{"72 bytes", strings.Repeat("a", 72), false},
+		{"73 bytes", strings.Repeat("a", 73), false},
+		{"too long with correct prefix", "correct horse battery staple" + strings.Repeat("!", 100), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := b.Verify(tt.password, encoded)
+			if err != nil {
+				t.Fatalf("Verify() error = %v, want nil", err)
+			}
+			if ok != tt.want {
+				t.Errorf("Verify() = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+// 当前算法为 argon2id 时，旧的 bcrypt 哈希遇到超长密码同样只是校验失败
+func TestPolicyVerifyLongPasswordAgainstLegacyBcrypt(t *testing.T) {
+	cfg := config.Default().Auth.PasswordHashing
+	cfg.Argon2Memory = 1024
+	cfg.Argon2Iterations = 1
+	cfg.BcryptCost = 4
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := (&Bcrypt{Cost: 4}).Hash("legacy password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, needsRehash, err := p.Verify(strings.Repeat("x", 100), legacy)
+	if err != nil || ok || needsRehash {
+		t.Errorf("Verify() = %v, %v, %v, want false, false, nil", ok, needsRehash, err)
+	}
+}
@@ -0,0 +1,112 @@
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachedChecker 检查密码是否出现在已泄露的密码库中
+type BreachedChecker interface {
+	Breached(password string) (bool, error)
+}
+
+// OpenBreached 打开本地泄露密码库，格式与 Have I Been Pwned 的 Pwned Passwords 一致：
+//   - 目录：每个 SHA-1 前 5 位对应一个 <前缀>.txt 文件，每行为 "后 35 位:次数"，按需读取，适合完整的数据集
+//   - 文件：每行为 "完整 SHA-1:次数"（次数可省略），启动时全部载入内存，适合精简过的常见密码列表
+func OpenBreached(path string) (BreachedChecker, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开泄露密码库失败：%w", err)
+	}
+	if info.IsDir() {
+		return &RangeDir{Dir: path}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开泄露密码库失败：%w", err)
+	}
+	defer f.Close()
+	return LoadHashList(f)
+}
+
+// sha1Range 计算密码的 SHA-1，拆分为 5 位前缀和 35 位后缀（大写十六进制）
+func sha1Range(password string) (prefix string, suffix string) {
+	sum := sha1.Sum([]byte(password))
+	h := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return h[:5], h[5:]
+}
+
+// RangeDir 按前缀分文件存放的泄露密码库，每次只读取一个前缀文件，
+// 与在线 k-anonymity 接口的查询方式相同
+type RangeDir struct {
+	Dir string
+}
+
+func (d *RangeDir) Breached(password string) (bool, error) {
+	prefix, suffix := sha1Range(password)
+	f, err := os.Open(filepath.Join(d.Dir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// HashList 载入内存的泄露密码库，按前缀分组保存后缀
+type HashList struct {
+	ranges map[string]map[string]struct{}
+}
+
+// LoadHashList 读取每行一个 SHA-1（可带 ":次数"）的列表，空行和 # 开头的注释会被忽略
+func LoadHashList(r io.Reader) (*HashList, error) {
+	list := &HashList{ranges: make(map[string]map[string]struct{})}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != 40 {
+			return nil, fmt.Errorf("泄露密码库第 %d 行不是有效的 SHA-1", lineNo)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("泄露密码库第 %d 行不是有效的 SHA-1", lineNo)
+		}
+		prefix, suffix := hash[:5], hash[5:]
+		if list.ranges[prefix] == nil {
+			list.ranges[prefix] = make(map[string]struct{})
+		}
+		list.ranges[prefix][suffix] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (l *HashList) Breached(password string) (bool, error) {
+	prefix, suffix := sha1Range(password)
+	_, ok := l.ranges[prefix][suffix]
+	return ok, nil
+}
@@ -0,0 +1,74 @@
+package passwords
+
+import (
+	"errors"
+	"fmt"
+	"goDemo/config"
+	"strings"
+)
+
+// ErrUnknownHash 数据库中的哈希不属于任何已支持的算法
+var ErrUnknownHash = errors.New("无法识别的密码哈希格式")
+
+// Hasher 一种密码哈希算法，编码结果自带算法和参数，便于以后调整参数或更换算法
+type Hasher interface {
+	// Hash 生成带参数的编码哈希
+	Hash(password string) (string, error)
+	// Recognizes 是否为本算法生成的哈希
+	Recognizes(encoded string) bool
+	// Verify 校验密码是否匹配
+	Verify(password string, encoded string) (bool, error)
+	// Outdated 哈希使用的参数是否与当前配置不同
+	Outdated(encoded string) bool
+	// MaxBytes 算法能处理的最大密码字节数，0 表示不限制
+	MaxBytes() int
+}
+
+// Policy 新密码统一使用 Current 算法，校验时兼容所有已支持的算法
+type Policy struct {
+	Current Hasher
+	hashers []Hasher
+}
+
+// New 根据配置创建密码哈希策略
+func New(cfg config.PasswordHashingConfig) (*Policy, error) {
+	argon := &Argon2id{
+		Memory:      uint32(cfg.Argon2Memory),
+		Iterations:  uint32(cfg.Argon2Iterations),
+		Parallelism: uint8(cfg.Argon2Parallelism),
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+	bcryptHasher := &Bcrypt{Cost: cfg.BcryptCost}
+	p := &Policy{hashers: []Hasher{argon, bcryptHasher}}
+	switch cfg.Algorithm {
+	case "argon2id":
+		p.Current = argon
+	case "bcrypt":
+		p.Current = bcryptHasher
+	default:
+		return nil, fmt.Errorf("不支持的密码哈希算法：%s", cfg.Algorithm)
+	}
+	return p, nil
+}
+
+// Hash 使用当前算法生成哈希
+func (p *Policy) Hash(password string) (string, error) {
+	return p.Current.Hash(password)
+}
+
+// Verify 校验密码，needsRehash 表示哈希使用的是旧算法或旧参数，调用方应在校验成功后重新哈希
+func (p *Policy) Verify(password string, encoded string) (ok bool, needsRehash bool, err error) {
+	encoded = strings.TrimSpace(encoded)
+	for _, h := range p.hashers {
+		if !h.Recognizes(encoded) {
+			continue
+		}
+		ok, err = h.Verify(password, encoded)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, h != p.Current || h.Outdated(encoded), nil
+	}
+	return false, false, ErrUnknownHash
+}
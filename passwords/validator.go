@@ -0,0 +1,157 @@
+package passwords
+
+import (
+	"fmt"
+	"goDemo/config"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Violation 密码不符合策略的一项原因，Code 供客户端识别，Message 直接展示给用户
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError 新密码不符合策略，包含所有不满足的规则
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return "密码不符合要求：" + strings.Join(messages, "；")
+}
+
+// Messages 所有原因的提示文字
+func (e *ValidationError) Messages() []string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return messages
+}
+
+// Validator 校验新密码是否符合密码策略
+type Validator struct {
+	MinLength        int
+	MaxLength        int
+	MaxBytes         int // 由哈希算法决定，0 表示不限制
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// DisallowUserInfo 密码中不能包含用户名或邮箱
+	DisallowUserInfo bool
+	// Breached 泄露密码库，为空时不检查
+	Breached BreachedChecker
+}
+
+// NewValidator 根据配置创建密码策略，hashing 为当前使用的哈希策略
+func NewValidator(cfg config.PasswordPolicyConfig, hashing *Policy) (*Validator, error) {
+	v := &Validator{
+		MinLength:        cfg.MinLength,
+		MaxLength:        cfg.MaxLength,
+		MaxBytes:         hashing.Current.MaxBytes(),
+		RequireUppercase: cfg.RequireUppercase,
+		RequireLowercase: cfg.RequireLowercase,
+		RequireDigit:     cfg.RequireDigit,
+		RequireSymbol:    cfg.RequireSymbol,
+		DisallowUserInfo: cfg.DisallowUserInfo,
+	}
+	if cfg.BreachedPasswordsPath != "" {
+		checker, err := OpenBreached(cfg.BreachedPasswordsPath)
+		if err != nil {
+			return nil, err
+		}
+		v.Breached = checker
+	}
+	return v, nil
+}
+
+// Validate 校验密码，identifiers 为用户名、邮箱等不能出现在密码中的信息
+// 不符合策略时返回 *ValidationError
+func (v *Validator) Validate(password string, identifiers ...string) error {
+	var violations []Violation
+	add := func(code string, format string, args ...interface{}) {
+		violations = append(violations, Violation{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < v.MinLength {
+		add("too_short", "密码至少需要 %d 个字符", v.MinLength)
+	}
+	if v.MaxLength > 0 && length > v.MaxLength {
+		add("too_long", "密码不能超过 %d 个字符", v.MaxLength)
+	} else if v.MaxBytes > 0 && len(password) > v.MaxBytes {
+		add("too_long", "密码过长，不能超过 %d 个字节", v.MaxBytes)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if v.RequireUppercase && !hasUpper {
+		add("missing_uppercase", "密码需要包含大写字母")
+	}
+	if v.RequireLowercase && !hasLower {
+		add("missing_lowercase", "密码需要包含小写字母")
+	}
+	if v.RequireDigit && !hasDigit {
+		add("missing_digit", "密码需要包含数字")
+	}
+	if v.RequireSymbol && !hasSymbol {
+		add("missing_symbol", "密码需要包含符号")
+	}
+
+	if v.DisallowUserInfo && containsUserInfo(password, identifiers) {
+		add("contains_user_info", "密码不能包含用户名或邮箱")
+	}
+
+	// 其他规则已不满足时不再查询泄露库，避免无谓的磁盘读取
+	if len(violations) == 0 && v.Breached != nil {
+		breached, err := v.Breached.Breached(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			add("breached", "该密码出现在已泄露的密码库中，请换一个")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// containsUserInfo 忽略大小写判断密码是否包含用户名、邮箱或邮箱的用户名部分，过短的片段不参与比较
+func containsUserInfo(password string, identifiers []string) bool {
+	lower := strings.ToLower(password)
+	for _, id := range identifiers {
+		id = strings.ToLower(strings.TrimSpace(id))
+		candidates := []string{id}
+		if at := strings.LastIndex(id, "@"); at > 0 {
+			candidates = append(candidates, id[:at])
+		}
+		for _, c := range candidates {
+			if utf8.RuneCountInString(c) >= 3 && strings.Contains(lower, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"errors"
+	"goDemo/models"
+	"slices"
+)
+
+// ErrForbidden 已登录但没有权限执行该操作
+var ErrForbidden = errors.New("无权执行该操作")
+
+// 角色
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permission 权限
+type Permission string
+
+const (
+	// ModerateArticles 编辑、删除他人的文章
+	ModerateArticles Permission = "articles:moderate"
+	// ModerateComments 删除他人的评论
+	ModerateComments Permission = "comments:moderate"
+	// ManageRoles 查看用户角色并分配角色
+	ManageRoles Permission = "roles:manage"
+	// ManageUsers 管理用户账号，例如解除登录锁定
+	ManageUsers Permission = "users:manage"
+	// ImpersonateUsers 代入其他用户的身份排查问题
+	ImpersonateUsers Permission = "users:impersonate"
+	// ViewAuditLog 查看审计日志
+	ViewAuditLog Permission = "audit:read"
+	// ManageInvitations 不受数量和次数限制地创建邀请码，查看和吊销所有邀请码
+	ManageInvitations Permission = "invitations:manage"
+)
+
+// rolePermissions 角色拥有的权限，普通用户只能操作自己的内容
+var rolePermissions = map[string][]Permission{
+	RoleUser:      {},
+	RoleModerator: {ModerateArticles, ModerateComments},
+	RoleAdmin:     {ModerateArticles, ModerateComments, ManageRoles, ManageUsers, ImpersonateUsers, ViewAuditLog, ManageInvitations},
+}
+
+// Roles 返回所有角色
+func Roles() []string {
+	return []string{RoleUser, RoleModerator, RoleAdmin}
+}
+
+// ValidRole 是否为已定义的角色
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Can 用户是否拥有指定权限，未登录用户没有任何权限
+func Can(user *models.UserModel, permission Permission) bool {
+	if user == nil {
+		return false
+	}
+	return slices.Contains(rolePermissions[user.Role], permission)
+}
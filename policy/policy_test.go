@@ -0,0 +1,313 @@
+package policy
+
+import (
+	"goDemo/models"
+	"testing"
+)
+
+func user(id uint, role string) *models.UserModel {
+	u := &models.UserModel{Role: role}
+	u.ID = id
+	return u
+}
+
+var (
+	author    = user(1, RoleUser)
+	owner     = user(2, RoleUser)
+	other     = user(3, RoleUser)
+	moderator = user(4, RoleModerator)
+	admin     = user(5, RoleAdmin)
+)
+
+func article(authorID uint, status string) *models.Article {
+	a := &models.Article{AuthorID: authorID, Status: status}
+	a.ID = 10
+	return a
+}
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      *models.UserModel
+		permission Permission
+		want       bool
+	}{
+		{"nil actor", nil, ModerateArticles, false},
+		{"user moderate articles", other, ModerateArticles, false},
+		{"moderator moderate articles", moderator, ModerateArticles, true},
+		{"moderator moderate comments", moderator, ModerateComments, true},
+		{"moderator manage roles", moderator, ManageRoles, false},
+		{"moderator manage users", moderator, ManageUsers, false},
+		{"admin manage roles", admin, ManageRoles, true},
+		{"admin manage users", admin, ManageUsers, true},
+		{"admin impersonate", admin, ImpersonateUsers, true},
+		{"unknown role", user(9, "ghost"), ModerateArticles, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Can(tt.actor, tt.permission); got != tt.want {
+				t.Errorf("Can() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanViewArticle(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   *models.UserModel
+		article *models.Article
+		want    bool
+	}{
+		{"nil article", author, nil, false},
+		{"published author", author, article(author.ID, models.ArticlePublished), true},
+		{"published other", other, article(author.ID, models.ArticlePublished), true},
+		{"published nil actor", nil, article(author.ID, models.ArticlePublished), true},
+		{"unlisted other", other, article(author.ID, models.ArticleUnlisted), true},
+		{"unlisted nil actor", nil, article(author.ID, models.ArticleUnlisted), true},
+		{"archived nil actor", nil, article(author.ID, models.ArticleArchived), true},
+		{"draft author", author, article(author.ID, models.ArticleDraft), true},
+		{"draft other", other, article(author.ID, models.ArticleDraft), false},
+		{"draft moderator", moderator, article(author.ID, models.ArticleDraft), false},
+		{"draft admin", admin, article(author.ID, models.ArticleDraft), false},
+		{"draft nil actor", nil, article(author.ID, models.ArticleDraft), false},
+		{"scheduled author", author, article(author.ID, models.ArticleScheduled), true},
+		{"scheduled other", other, article(author.ID, models.ArticleScheduled), false},
+		{"scheduled moderator", moderator, article(author.ID, models.ArticleScheduled), false},
+		{"scheduled admin", admin, article(author.ID, models.ArticleScheduled), false},
+		{"scheduled nil actor", nil, article(author.ID, models.ArticleScheduled), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanViewArticle(tt.actor, tt.article); got != tt.want {
+				t.Errorf("CanViewArticle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanCreateArticle(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor *models.UserModel
+		want  bool
+	}{
+		{"user", other, true},
+		{"moderator", moderator, true},
+		{"admin", admin, true},
+		{"nil actor", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanCreateArticle(tt.actor); got != tt.want {
+				t.Errorf("CanCreateArticle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanEditArticle(t *testing.T) {
+	published := article(author.ID, models.ArticlePublished)
+	tests := []struct {
+		name    string
+		actor   *models.UserModel
+		article *models.Article
+		want    bool
+	}{
+		{"author", author, published, true},
+		{"other", other, published, false},
+		{"moderator", moderator, published, true},
+		{"admin", admin, published, true},
+		{"nil actor", nil, published, false},
+		{"nil article", author, nil, false},
+		{"author draft", author, article(author.ID, models.ArticleDraft), true},
+		{"moderator draft", moderator, article(author.ID, models.ArticleDraft), true},
+		{"other draft", other, article(author.ID, models.ArticleDraft), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanEditArticle(tt.actor, tt.article); got != tt.want {
+				t.Errorf("CanEditArticle() = %v, want %v", got, tt.want)
+			}
+			// 删除文章和查看历史版本与编辑文章的规则相同
+			if got := CanDeleteArticle(tt.actor, tt.article); got != tt.want {
+				t.Errorf("CanDeleteArticle() = %v, want %v", got, tt.want)
+			}
+			if got := CanViewRevisions(tt.actor, tt.article); got != tt.want {
+				t.Errorf("CanViewRevisions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   *models.UserModel
+		article *models.Article
+		want    bool
+	}{
+		{"author published", author, article(author.ID, models.ArticlePublished), true},
+		{"other published", other, article(author.ID, models.ArticlePublished), true},
+		{"moderator published", moderator, article(author.ID, models.ArticlePublished), true},
+		{"admin published", admin, article(author.ID, models.ArticlePublished), true},
+		{"nil actor published", nil, article(author.ID, models.ArticlePublished), false},
+		{"other unlisted", other, article(author.ID, models.ArticleUnlisted), true},
+		{"other archived", other, article(author.ID, models.ArticleArchived), false},
+		{"author draft", author, article(author.ID, models.ArticleDraft), false},
+		{"author scheduled", author, article(author.ID, models.ArticleScheduled), false},
+		{"nil article", other, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanComment(tt.actor, tt.article); got != tt.want {
+				t.Errorf("CanComment() = %v, want %v", got, tt.want)
+			}
+			// 收藏与评论的规则相同
+			if got := CanFavorite(tt.actor, tt.article); got != tt.want {
+				t.Errorf("CanFavorite() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanUnfavorite(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   *models.UserModel
+		article *models.Article
+		want    bool
+	}{
+		{"other published", other, article(author.ID, models.ArticlePublished), true},
+		{"other archived", other, article(author.ID, models.ArticleArchived), true},
+		{"author draft", author, article(author.ID, models.ArticleDraft), true},
+		{"nil actor", nil, article(author.ID, models.ArticlePublished), false},
+		{"nil article", other, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanUnfavorite(tt.actor, tt.article); got != tt.want {
+				t.Errorf("CanUnfavorite() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanDeleteComment(t *testing.T) {
+	// author 写的评论，评论在 owner 的文章下
+	onOwners := article(owner.ID, models.ArticlePublished)
+	comment := &models.Comment{AuthorID: author.ID, ArticleID: onOwners.ID}
+	elsewhere := &models.Comment{AuthorID: author.ID, ArticleID: onOwners.ID + 1}
+	tests := []struct {
+		name    string
+		actor   *models.UserModel
+		comment *models.Comment
+		article *models.Article
+		want    bool
+	}{
+		{"comment author", author, comment, onOwners, true},
+		{"article owner", owner, comment, onOwners, true},
+		{"other", other, comment, onOwners, false},
+		{"moderator", moderator, comment, onOwners, true},
+		{"admin", admin, comment, onOwners, true},
+		{"nil actor", nil, comment, onOwners, false},
+		{"nil comment", author, nil, onOwners, false},
+		{"nil article", author, comment, nil, false},
+		{"comment on another article", moderator, elsewhere, onOwners, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanDeleteComment(tt.actor, tt.comment, tt.article); got != tt.want {
+				t.Errorf("CanDeleteComment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanUpdateRole(t *testing.T) {
+	otherAdmin := user(6, RoleAdmin)
+	tests := []struct {
+		name   string
+		actor  *models.UserModel
+		target *models.UserModel
+		want   bool
+	}{
+		{"admin on user", admin, other, true},
+		{"admin on moderator", admin, moderator, true},
+		{"admin on other admin", admin, otherAdmin, true},
+		{"admin on self", admin, admin, false},
+		{"moderator on user", moderator, other, false},
+		{"user on user", author, other, false},
+		{"nil actor", nil, other, false},
+		{"nil target", admin, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanUpdateRole(tt.actor, tt.target); got != tt.want {
+				t.Errorf("CanUpdateRole() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanImpersonate(t *testing.T) {
+	otherAdmin := user(6, RoleAdmin)
+	tests := []struct {
+		name   string
+		actor  *models.UserModel
+		target *models.UserModel
+		want   bool
+	}{
+		{"admin on user", admin, other, true},
+		{"admin on moderator", admin, moderator, true},
+		{"admin on other admin", admin, otherAdmin, false},
+		{"admin on self", admin, admin, false},
+		{"moderator on user", moderator, other, false},
+		{"user on user", author, other, false},
+		{"nil actor", nil, other, false},
+		{"nil target", admin, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanImpersonate(tt.actor, tt.target); got != tt.want {
+				t.Errorf("CanImpersonate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanRevokeInvitation(t *testing.T) {
+	mine := &models.Invitation{InviterID: &author.ID}
+	system := &models.Invitation{}
+	tests := []struct {
+		name       string
+		actor      *models.UserModel
+		invitation *models.Invitation
+		want       bool
+	}{
+		{"inviter", author, mine, true},
+		{"other", other, mine, false},
+		{"moderator", moderator, mine, false},
+		{"admin", admin, mine, true},
+		{"admin on system invitation", admin, system, true},
+		{"user on system invitation", author, system, false},
+		{"nil actor", nil, mine, false},
+		{"nil invitation", admin, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanRevokeInvitation(tt.actor, tt.invitation); got != tt.want {
+				t.Errorf("CanRevokeInvitation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	if err := Authorize(true); err != nil {
+		t.Errorf("Authorize(true) = %v, want nil", err)
+	}
+	if err := Authorize(false); err != ErrForbidden {
+		t.Errorf("Authorize(false) = %v, want ErrForbidden", err)
+	}
+}
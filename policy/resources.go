@@ -0,0 +1,99 @@
+package policy
+
+import "goDemo/models"
+
+// 资源级授权规则，所有修改数据的服务方法都应通过这里判断，而不是各自比较 AuthorID
+
+// Authorize 把判断结果转换为错误，便于服务层直接返回
+func Authorize(allowed bool) error {
+	if !allowed {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CanCreateArticle 登录用户可以发布文章
+func CanCreateArticle(actor *models.UserModel) bool {
+	return actor != nil
+}
+
+// CanViewArticle 草稿和定时发布的文章只有作者可以查看，其他状态的文章任何人都可以通过链接查看
+func CanViewArticle(actor *models.UserModel, article *models.Article) bool {
+	if article == nil {
+		return false
+	}
+	if article.Status == models.ArticleDraft || article.Status == models.ArticleScheduled {
+		return actor != nil && article.AuthorID == actor.ID
+	}
+	return true
+}
+
+// CanEditArticle 文章作者或拥有文章管理权限的用户可以编辑文章
+func CanEditArticle(actor *models.UserModel, article *models.Article) bool {
+	if actor == nil || article == nil {
+		return false
+	}
+	return article.AuthorID == actor.ID || Can(actor, ModerateArticles)
+}
+
+// CanViewRevisions 可以编辑文章的用户才能查看、比较和恢复历史版本
+func CanViewRevisions(actor *models.UserModel, article *models.Article) bool {
+	return CanEditArticle(actor, article)
+}
+
+// CanDeleteArticle 文章作者或拥有文章管理权限的用户可以删除文章
+func CanDeleteArticle(actor *models.UserModel, article *models.Article) bool {
+	return CanEditArticle(actor, article)
+}
+
+// CanComment 登录用户可以评论已发布或不公开列出的文章
+func CanComment(actor *models.UserModel, article *models.Article) bool {
+	return actor != nil && article != nil && interactive(article)
+}
+
+// CanDeleteComment 评论作者、文章作者或拥有评论管理权限的用户可以删除评论
+func CanDeleteComment(actor *models.UserModel, comment *models.Comment, article *models.Article) bool {
+	if actor == nil || comment == nil || article == nil || comment.ArticleID != article.ID {
+		return false
+	}
+	return comment.AuthorID == actor.ID || article.AuthorID == actor.ID || Can(actor, ModerateComments)
+}
+
+// CanFavorite 登录用户可以收藏已发布或不公开列出的文章
+func CanFavorite(actor *models.UserModel, article *models.Article) bool {
+	return actor != nil && article != nil && interactive(article)
+}
+
+// CanUnfavorite 登录用户可以随时取消收藏，包括已归档的文章
+func CanUnfavorite(actor *models.UserModel, article *models.Article) bool {
+	return actor != nil && article != nil
+}
+
+// interactive 草稿和已归档的文章不接受评论和收藏
+func interactive(article *models.Article) bool {
+	return article.Status == models.ArticlePublished || article.Status == models.ArticleUnlisted
+}
+
+// CanUpdateRole 拥有角色管理权限的用户可以修改他人的角色，不能修改自己的
+func CanUpdateRole(actor *models.UserModel, target *models.UserModel) bool {
+	if actor == nil || target == nil || actor.ID == target.ID {
+		return false
+	}
+	return Can(actor, ManageRoles)
+}
+
+// CanImpersonate 拥有代入权限的用户可以代入他人，不能代入自己，也不能代入同样拥有代入权限的用户
+func CanImpersonate(actor *models.UserModel, target *models.UserModel) bool {
+	if actor == nil || target == nil || actor.ID == target.ID {
+		return false
+	}
+	return Can(actor, ImpersonateUsers) && !Can(target, ImpersonateUsers)
+}
+
+// CanRevokeInvitation 邀请码的创建者或拥有邀请码管理权限的用户可以吊销邀请码
+func CanRevokeInvitation(actor *models.UserModel, invitation *models.Invitation) bool {
+	if actor == nil || invitation == nil {
+		return false
+	}
+	return (invitation.InviterID != nil && *invitation.InviterID == actor.ID) || Can(actor, ManageInvitations)
+}
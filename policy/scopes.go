@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scope API 密钥的授权范围，格式为 资源:read 或 资源:write
+// 通过 API 密钥访问时，接口必须声明所需的范围，没有声明的接口（账号设置、管理后台等）一律拒绝
+type Scope string
+
+const (
+	ScopeUserRead      Scope = "user:read"
+	ScopeProfilesRead  Scope = "profiles:read"
+	ScopeProfilesWrite Scope = "profiles:write"
+	ScopeArticlesRead  Scope = "articles:read"
+	ScopeArticlesWrite Scope = "articles:write"
+	ScopeCommentsRead  Scope = "comments:read"
+	ScopeCommentsWrite Scope = "comments:write"
+)
+
+var (
+	ErrUnknownScope = errors.New("未知的授权范围")
+	// ErrAPIKeyNotAllowed 账号设置、管理后台等接口只接受登录令牌
+	ErrAPIKeyNotAllowed = errors.New("该操作不支持使用 API 密钥")
+	ErrMissingScope     = errors.New("API 密钥缺少所需的授权范围")
+	// ErrImpersonationNotAllowed 代入用户身份时同样不能访问账号设置、管理后台等接口
+	ErrImpersonationNotAllowed = errors.New("代入用户身份时不能执行该操作")
+)
+
+// Scopes 返回所有可授予 API 密钥的范围
+func Scopes() []Scope {
+	return []Scope{
+		ScopeUserRead,
+		ScopeProfilesRead, ScopeProfilesWrite,
+		ScopeArticlesRead, ScopeArticlesWrite,
+		ScopeCommentsRead, ScopeCommentsWrite,
+	}
+}
+
+// ParseScopes 校验并去重，存在未定义的范围时返回错误
+func ParseScopes(values []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(values))
+	for _, value := range values {
+		scope := Scope(strings.TrimSpace(value))
+		if !slices.Contains(Scopes(), scope) {
+			return nil, fmt.Errorf("%w：%s", ErrUnknownScope, value)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// GrantsScope granted 是否包含 required，资源的 write 范围同时包含 read
+func GrantsScope(granted []Scope, required Scope) bool {
+	if slices.Contains(granted, required) {
+		return true
+	}
+	if resource, ok := strings.CutSuffix(string(required), ":read"); ok {
+		return slices.Contains(granted, Scope(resource+":write"))
+	}
+	return false
+}
+
+// AuthorizeScopes 校验 API 密钥的授权范围是否满足接口要求，required 为空表示接口不接受 API 密钥
+func AuthorizeScopes(granted []Scope, required []Scope) error {
+	if len(required) == 0 {
+		return ErrAPIKeyNotAllowed
+	}
+	for _, scope := range required {
+		if !GrantsScope(granted, scope) {
+			return fmt.Errorf("%w：%s", ErrMissingScope, scope)
+		}
+	}
+	return nil
+}
+
+// AuthorizeImpersonation 代入令牌拥有被代入用户的全部授权范围，但和 API 密钥一样只能访问声明了范围的接口
+func AuthorizeImpersonation(required []Scope) error {
+	if len(required) == 0 {
+		return ErrImpersonationNotAllowed
+	}
+	return nil
+}
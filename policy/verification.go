@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"goDemo/models"
+)
+
+// ErrEmailNotVerified 邮箱未验证，当前配置不允许执行该操作
+var ErrEmailNotVerified = errors.New("请先验证邮箱")
+
+// Action 可以限制未验证邮箱用户执行的操作
+type Action string
+
+const (
+	ActionCreateArticle Action = "create_article"
+	ActionComment       Action = "comment"
+	ActionFavorite      Action = "favorite"
+	ActionFollow        Action = "follow"
+)
+
+// Actions 返回所有可限制的操作
+func Actions() []Action {
+	return []Action{ActionCreateArticle, ActionComment, ActionFavorite, ActionFollow}
+}
+
+// VerificationPolicy 未验证邮箱的用户被限制执行的操作
+type VerificationPolicy struct {
+	restricted map[Action]bool
+}
+
+// NewVerificationPolicy 根据配置中的操作名创建策略，未知的操作名返回错误
+func NewVerificationPolicy(actions []string) (VerificationPolicy, error) {
+	known := make(map[Action]bool)
+	for _, a := range Actions() {
+		known[a] = true
+	}
+	p := VerificationPolicy{restricted: make(map[Action]bool)}
+	for _, a := range actions {
+		if !known[Action(a)] {
+			return VerificationPolicy{}, fmt.Errorf("未知的操作：%s", a)
+		}
+		p.restricted[Action(a)] = true
+	}
+	return p, nil
+}
+
+// Allows 用户是否可以执行该操作，已验证邮箱的用户不受限制
+func (p VerificationPolicy) Allows(user *models.UserModel, action Action) bool {
+	if user == nil {
+		return false
+	}
+	return user.EmailVerified() || !p.restricted[action]
+}
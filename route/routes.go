@@ -3,13 +3,21 @@ package route
 import (
 	"github.com/gin-gonic/gin"
 	"goDemo/controller"
+	"goDemo/lockout"
+	"goDemo/policy"
 	"goDemo/service"
 	"goDemo/utils"
 )
 
+// JWKSRoutes 公开签名公钥，供其他服务验证令牌
+func JWKSRoutes(router *gin.Engine, Auth *utils.Auth) {
+	keysController := &controller.KeysController{Auth: Auth}
+	router.GET("/.well-known/jwks.json", keysController.JWKS)
+}
+
 // SetupRoutes  注册
-func SetupRoutes(router *gin.Engine, UserService *service.UserService, Auth *utils.Auth) {
-	userController := &controller.UserController{UserService: UserService, Auth: Auth}
+func SetupRoutes(router *gin.Engine, UserService *service.UserService, VerificationService *service.VerificationService) {
+	userController := &controller.UserController{UserService: UserService, VerificationService: VerificationService}
 	api := router.Group("/api")
 	{
 		api.POST("/users/register", userController.RegisterUser)
@@ -17,154 +25,327 @@ func SetupRoutes(router *gin.Engine, UserService *service.UserService, Auth *uti
 }
 
 // LoginRoutes 登录
-func LoginRoutes(router *gin.Engine, UserService *service.UserService, Auth *utils.Auth) {
-	userController := &controller.UserController{UserService: UserService, Auth: Auth}
+func LoginRoutes(router *gin.Engine, UserService *service.UserService, TokenService *service.TokenService, TwoFactorService *service.TwoFactorService, Lockout *lockout.Limiter) {
+	userController := &controller.UserController{UserService: UserService, TokenService: TokenService, TwoFactorService: TwoFactorService, Lockout: Lockout}
 	api := router.Group("/api")
 	{
 		api.POST("/users/login", userController.LoginUser)
+		api.POST("/users/login/2fa", userController.LoginTwoFactor)
+	}
+}
+
+// TwoFactorRoutes 两步验证管理
+func TwoFactorRoutes(router *gin.Engine, TwoFactorService *service.TwoFactorService, Lockout *lockout.Limiter, AuthMiddleware *utils.AuthMiddleware) {
+	userController := &controller.UserController{TwoFactorService: TwoFactorService, Lockout: Lockout}
+	api := router.Group("/api")
+	{
+		api.POST("/user/2fa/enroll", AuthMiddleware.RequireAuth(), userController.EnrollTwoFactor)
+		api.POST("/user/2fa/confirm", AuthMiddleware.RequireAuth(), userController.ConfirmTwoFactor)
+		api.POST("/user/2fa/disable", AuthMiddleware.RequireAuth(), userController.DisableTwoFactor)
+		api.POST("/user/2fa/recovery-codes", AuthMiddleware.RequireAuth(), userController.RegenerateRecoveryCodes)
+	}
+}
+
+// APIKeyRoutes 个人 API 密钥管理，只接受登录令牌，避免用密钥再派生密钥
+func APIKeyRoutes(router *gin.Engine, APIKeyService *service.APIKeyService, AuthMiddleware *utils.AuthMiddleware) {
+	apiKeyController := &controller.APIKeyController{APIKeyService: APIKeyService}
+	api := router.Group("/api")
+	{
+		api.POST("/user/api-keys", AuthMiddleware.RequireAuth(), apiKeyController.CreateAPIKey)
+		api.GET("/user/api-keys", AuthMiddleware.RequireAuth(), apiKeyController.ListAPIKeys)
+		api.DELETE("/user/api-keys/:id", AuthMiddleware.RequireAuth(), apiKeyController.RevokeAPIKey)
+	}
+}
+
+// OIDCRoutes 第三方登录与账号关联
+func OIDCRoutes(router *gin.Engine, OIDCService *service.OIDCService, TokenService *service.TokenService, TwoFactorService *service.TwoFactorService, AuthMiddleware *utils.AuthMiddleware) {
+	oidcController := &controller.OIDCController{OIDCService: OIDCService, TokenService: TokenService, TwoFactorService: TwoFactorService}
+	api := router.Group("/api")
+	{
+		api.GET("/users/oidc/providers", oidcController.ListProviders)
+		api.POST("/users/oidc/:provider/authorize", AuthMiddleware.OptionalAuth(), oidcController.Authorize)
+		api.POST("/users/oidc/:provider/callback", oidcController.Callback)
+		api.GET("/user/identities", AuthMiddleware.RequireAuth(), oidcController.ListIdentities)
+		api.DELETE("/user/identities/:provider", AuthMiddleware.RequireAuth(), oidcController.UnlinkIdentity)
+	}
+}
+
+// AccountRoutes 个人数据导出与账号注销，只接受登录令牌
+func AccountRoutes(router *gin.Engine, AccountService *service.AccountService, AuthMiddleware *utils.AuthMiddleware) {
+	accountController := &controller.AccountController{AccountService: AccountService}
+	api := router.Group("/api")
+	{
+		api.GET("/user/export", AuthMiddleware.RequireAuth(), accountController.ExportData)
+		api.DELETE("/user", AuthMiddleware.RequireAuth(), accountController.DeleteAccount)
+		api.POST("/user/deletion/cancel", AuthMiddleware.RequireAuth(), accountController.CancelDeletion)
 	}
 }
 
 // GetCurrentUserRoutes 获取当前用户
-func GetCurrentUserRoutes(router *gin.Engine, UserService *service.UserService, Auth *utils.Auth) {
-	userController := &controller.UserController{UserService: UserService, Auth: Auth}
+func GetCurrentUserRoutes(router *gin.Engine, UserService *service.UserService, AuthMiddleware *utils.AuthMiddleware) {
+	userController := &controller.UserController{UserService: UserService}
 	api := router.Group("/api")
 	{
-		api.GET("/user", userController.GetCurrentUser)
+		api.GET("/user", AuthMiddleware.RequireAuth(policy.ScopeUserRead), userController.GetCurrentUser)
 	}
 }
 
 // UpdateUserRoutes 更新用户
-func UpdateUserRoutes(router *gin.Engine, UserService *service.UserService, Auth *utils.Auth) {
-	userController := &controller.UserController{UserService: UserService, Auth: Auth}
+func UpdateUserRoutes(router *gin.Engine, UserService *service.UserService, TokenService *service.TokenService, VerificationService *service.VerificationService, AuthMiddleware *utils.AuthMiddleware) {
+	userController := &controller.UserController{UserService: UserService, TokenService: TokenService, VerificationService: VerificationService}
+	api := router.Group("/api")
+	{
+		api.PUT("/user", AuthMiddleware.RequireAuth(), userController.UpdateUser)
+	}
+}
+
+// RefreshTokenRoutes 刷新令牌
+func RefreshTokenRoutes(router *gin.Engine, UserService *service.UserService, TokenService *service.TokenService) {
+	userController := &controller.UserController{UserService: UserService, TokenService: TokenService}
+	api := router.Group("/api")
+	{
+		api.POST("/users/refresh", userController.RefreshToken)
+	}
+}
+
+// LogoutRoutes 退出登录
+func LogoutRoutes(router *gin.Engine, UserService *service.UserService, TokenService *service.TokenService, AuthMiddleware *utils.AuthMiddleware) {
+	userController := &controller.UserController{UserService: UserService, TokenService: TokenService}
+	api := router.Group("/api")
+	{
+		api.POST("/users/logout", AuthMiddleware.RequireAuth(), userController.Logout)
+	}
+}
+
+// SessionRoutes 登录会话管理
+func SessionRoutes(router *gin.Engine, TokenService *service.TokenService, AuthMiddleware *utils.AuthMiddleware) {
+	userController := &controller.UserController{TokenService: TokenService}
+	api := router.Group("/api")
+	{
+		api.GET("/user/sessions", AuthMiddleware.RequireAuth(), userController.ListSessions)
+		api.DELETE("/user/sessions", AuthMiddleware.RequireAuth(), userController.RevokeOtherSessions)
+		api.DELETE("/user/sessions/:id", AuthMiddleware.RequireAuth(), userController.RevokeSession)
+	}
+}
+
+// PasswordResetRoutes 找回密码
+func PasswordResetRoutes(router *gin.Engine, PasswordResetService *service.PasswordResetService) {
+	userController := &controller.UserController{PasswordResetService: PasswordResetService}
+	api := router.Group("/api")
+	{
+		api.POST("/users/password-reset", userController.RequestPasswordReset)
+		api.POST("/users/password-reset/confirm", userController.ConfirmPasswordReset)
+	}
+}
+
+// VerifyEmailRoutes 邮箱验证
+func VerifyEmailRoutes(router *gin.Engine, VerificationService *service.VerificationService, AuthMiddleware *utils.AuthMiddleware) {
+	userController := &controller.UserController{VerificationService: VerificationService}
 	api := router.Group("/api")
 	{
-		api.PUT("/user", userController.UpdateUser)
+		api.POST("/users/verify-email", userController.VerifyEmail)
+		api.POST("/users/verify-email/resend", AuthMiddleware.RequireAuth(), userController.ResendVerification)
 	}
 }
 
 // GetProfileRoutes 获取用户资料
-func GetProfileRoutes(router *gin.Engine, ProfileService *service.ProfileService, UserService *service.UserService, Auth *utils.Auth) {
-	profileController := &controller.ProfileController{ProfileService: ProfileService, UserService: UserService, Auth: Auth}
+func GetProfileRoutes(router *gin.Engine, ProfileService *service.ProfileService, UserService *service.UserService, AuthMiddleware *utils.AuthMiddleware) {
+	profileController := &controller.ProfileController{ProfileService: ProfileService, UserService: UserService}
 	api := router.Group("/api")
 	{
-		api.GET("/profiles/:username", profileController.GetProfile)
+		api.GET("/profiles/:username", AuthMiddleware.OptionalAuth(policy.ScopeProfilesRead), profileController.GetProfile)
 	}
 }
 
 // FollowUserRoutes 关注用户
-func FollowUserRoutes(router *gin.Engine, ProfileService *service.ProfileService, UserService *service.UserService, Auth *utils.Auth) {
-	profileController := &controller.ProfileController{ProfileService: ProfileService, UserService: UserService, Auth: Auth}
+func FollowUserRoutes(router *gin.Engine, ProfileService *service.ProfileService, UserService *service.UserService, AuthMiddleware *utils.AuthMiddleware) {
+	profileController := &controller.ProfileController{ProfileService: ProfileService, UserService: UserService}
 	api := router.Group("/api")
 	{
-		api.POST("/profiles/:username/follow", profileController.FollowUser)
+		api.POST("/profiles/:username/follow", AuthMiddleware.RequireAuth(policy.ScopeProfilesWrite), AuthMiddleware.RequireVerifiedEmail(policy.ActionFollow), profileController.FollowUser)
 	}
 }
 
 // UnfollowUserRoutes 取消关注用户
-func UnfollowUserRoutes(router *gin.Engine, ProfileService *service.ProfileService, UserService *service.UserService, Auth *utils.Auth) {
-	profileController := &controller.ProfileController{ProfileService: ProfileService, UserService: UserService, Auth: Auth}
+func UnfollowUserRoutes(router *gin.Engine, ProfileService *service.ProfileService, UserService *service.UserService, AuthMiddleware *utils.AuthMiddleware) {
+	profileController := &controller.ProfileController{ProfileService: ProfileService, UserService: UserService}
 	api := router.Group("/api")
 	{
-		api.DELETE("/profiles/:username/follow", profileController.UnfollowUser)
+		api.DELETE("/profiles/:username/follow", AuthMiddleware.RequireAuth(policy.ScopeProfilesWrite), AuthMiddleware.RequireVerifiedEmail(policy.ActionFollow), profileController.UnfollowUser)
 	}
 }
 
 // ListArticlesRoutes 文章列表
-func ListArticlesRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	articleController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func ListArticlesRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	articleController := &controller.ArticleController{ArticleService: ArticleService}
 	api := router.Group("/api")
 	{
-		api.GET("/articles", articleController.ListArticles)
+		api.GET("/articles", AuthMiddleware.OptionalAuth(policy.ScopeArticlesRead), articleController.ListArticles)
 	}
 }
 
 // FeedArticlesRoutes 关注文章列表
-func FeedArticlesRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	articleController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func FeedArticlesRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	articleController := &controller.ArticleController{ArticleService: ArticleService}
+	api := router.Group("/api")
+	{
+		api.GET("/articles/feed", AuthMiddleware.RequireAuth(policy.ScopeArticlesRead), articleController.FeedArticles)
+	}
+}
+
+// TagRoutes 热门标签，无需登录
+func TagRoutes(router *gin.Engine, TagService *service.TagService) {
+	tagController := &controller.TagController{TagService: TagService}
 	api := router.Group("/api")
 	{
-		api.GET("/articles/feed", articleController.FeedArticles)
+		api.GET("/tags", tagController.ListTags)
 	}
 }
 
 // GetArticleRoutes 获取文章
-func GetArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	articleController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func GetArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	articleController := &controller.ArticleController{ArticleService: ArticleService}
 	api := router.Group("/api")
 	{
-		api.GET("/articles/:slug", articleController.GetArticle)
+		api.GET("/articles/:slug", AuthMiddleware.OptionalAuth(policy.ScopeArticlesRead), articleController.GetArticle)
 	}
 }
 
 // CreateArticleRoutes 创建文章
-func CreateArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	articleController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func CreateArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	articleController := &controller.ArticleController{ArticleService: ArticleService}
 	api := router.Group("/api")
 	{
-		api.POST("/articles", articleController.CreateArticle)
+		api.POST("/articles", AuthMiddleware.RequireAuth(policy.ScopeArticlesWrite), AuthMiddleware.RequireVerifiedEmail(policy.ActionCreateArticle), articleController.CreateArticle)
 	}
 }
 
 // UpdateArticleRoutes 更新文章
-func UpdateArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	articleController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func UpdateArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	articleController := &controller.ArticleController{ArticleService: ArticleService}
+	api := router.Group("/api")
+	{
+		api.PUT("/articles/:slug", AuthMiddleware.RequireAuth(policy.ScopeArticlesWrite), articleController.UpdateArticle)
+	}
+}
+
+// PublishArticleRoutes 发布和取消发布文章
+func PublishArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	articleController := &controller.ArticleController{ArticleService: ArticleService}
+	api := router.Group("/api")
+	{
+		api.POST("/articles/:slug/publish", AuthMiddleware.RequireAuth(policy.ScopeArticlesWrite), AuthMiddleware.RequireVerifiedEmail(policy.ActionCreateArticle), articleController.PublishArticle)
+		api.DELETE("/articles/:slug/publish", AuthMiddleware.RequireAuth(policy.ScopeArticlesWrite), articleController.UnpublishArticle)
+	}
+}
+
+// UserArticlesRoutes 我的文章，包括草稿
+func UserArticlesRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	articleController := &controller.ArticleController{ArticleService: ArticleService}
+	api := router.Group("/api")
+	{
+		api.GET("/user/articles", AuthMiddleware.RequireAuth(policy.ScopeArticlesRead), articleController.ListUserArticles)
+	}
+}
+
+// RevisionRoutes 文章历史版本
+func RevisionRoutes(router *gin.Engine, RevisionService *service.RevisionService, AuthMiddleware *utils.AuthMiddleware) {
+	revisionController := &controller.RevisionController{RevisionService: RevisionService}
 	api := router.Group("/api")
 	{
-		api.PUT("/articles/:slug", articleController.UpdateArticle)
+		api.GET("/articles/:slug/revisions", AuthMiddleware.RequireAuth(policy.ScopeArticlesRead), revisionController.ListRevisions)
+		api.GET("/articles/:slug/revisions/:number", AuthMiddleware.RequireAuth(policy.ScopeArticlesRead), revisionController.GetRevision)
+		api.GET("/articles/:slug/diff", AuthMiddleware.RequireAuth(policy.ScopeArticlesRead), revisionController.DiffRevisions)
+		api.POST("/articles/:slug/revisions/:number/restore", AuthMiddleware.RequireAuth(policy.ScopeArticlesWrite), revisionController.RestoreRevision)
 	}
 }
 
 // DeleteArticleRoutes 删除文章
-func DeleteArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	articleController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func DeleteArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	articleController := &controller.ArticleController{ArticleService: ArticleService}
 	api := router.Group("/api")
 	{
-		api.DELETE("/articles/:slug", articleController.DeleteArticle)
+		api.DELETE("/articles/:slug", AuthMiddleware.RequireAuth(policy.ScopeArticlesWrite), articleController.DeleteArticle)
 	}
 }
 
 // AddCommentRoutes 添加文章评论
-func AddCommentRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	commentController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func AddCommentRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	commentController := &controller.ArticleController{ArticleService: ArticleService}
 	api := router.Group("/api")
 	{
-		api.POST("/articles/:slug/comments", commentController.AddComment)
+		api.POST("/articles/:slug/comments", AuthMiddleware.RequireAuth(policy.ScopeCommentsWrite), AuthMiddleware.RequireVerifiedEmail(policy.ActionComment), commentController.AddComment)
 	}
 }
 
 // GetCommentsRoutes 获取文章评论
-func GetCommentsRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	commentController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func GetCommentsRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	commentController := &controller.ArticleController{ArticleService: ArticleService}
 	api := router.Group("/api")
 	{
-		api.GET("/articles/:slug/comments", commentController.GetComments)
+		api.GET("/articles/:slug/comments", AuthMiddleware.OptionalAuth(policy.ScopeCommentsRead), commentController.GetComments)
 	}
 }
 
 // DeleteCommentRoutes 删除文章评论
-func DeleteCommentRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	commentController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func DeleteCommentRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	commentController := &controller.ArticleController{ArticleService: ArticleService}
 	api := router.Group("/api")
 	{
-		api.DELETE("/articles/:slug/comments/:id", commentController.DeleteComment)
+		api.DELETE("/articles/:slug/comments/:id", AuthMiddleware.RequireAuth(policy.ScopeCommentsWrite), commentController.DeleteComment)
 	}
 }
 
 // FavoriteArticleRoutes 收藏文章
-func FavoriteArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	favoriteController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func FavoriteArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	favoriteController := &controller.ArticleController{ArticleService: ArticleService}
 	api := router.Group("/api")
 	{
-		api.POST("/articles/:slug/favorite", favoriteController.FavoriteArticle)
+		api.POST("/articles/:slug/favorite", AuthMiddleware.RequireAuth(policy.ScopeArticlesWrite), AuthMiddleware.RequireVerifiedEmail(policy.ActionFavorite), favoriteController.FavoriteArticle)
 	}
 }
 
 // UnfavoriteArticleRoutes 取消收藏文章
-func UnfavoriteArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, Auth *utils.Auth) {
-	favoriteController := &controller.ArticleController{ArticleService: ArticleService, Auth: Auth}
+func UnfavoriteArticleRoutes(router *gin.Engine, ArticleService *service.ArticleService, AuthMiddleware *utils.AuthMiddleware) {
+	favoriteController := &controller.ArticleController{ArticleService: ArticleService}
+	api := router.Group("/api")
+	{
+		api.DELETE("/articles/:slug/favorite", AuthMiddleware.RequireAuth(policy.ScopeArticlesWrite), AuthMiddleware.RequireVerifiedEmail(policy.ActionFavorite), favoriteController.UnfavoriteArticle)
+	}
+}
+
+// AdminRoutes 管理后台，查看用户和分配角色需要角色管理权限，解除登录锁定需要用户管理权限
+func AdminRoutes(router *gin.Engine, ProfileService *service.ProfileService, AuthMiddleware *utils.AuthMiddleware) {
+	adminController := &controller.AdminController{ProfileService: ProfileService}
+	admin := router.Group("/api/admin", AuthMiddleware.RequireAuth())
+	{
+		admin.GET("/users", utils.RequirePermission(policy.ManageRoles), adminController.ListUsers)
+		admin.PUT("/users/:username/role", utils.RequirePermission(policy.ManageRoles), adminController.UpdateRole)
+		admin.POST("/users/:username/unlock", utils.RequirePermission(policy.ManageUsers), adminController.UnlockUser)
+	}
+}
+
+// ImpersonationRoutes 管理员代入用户身份与审计日志
+func ImpersonationRoutes(router *gin.Engine, ImpersonationService *service.ImpersonationService, AuthMiddleware *utils.AuthMiddleware) {
+	adminController := &controller.AdminController{ImpersonationService: ImpersonationService}
+	admin := router.Group("/api/admin", AuthMiddleware.RequireAuth())
+	{
+		admin.POST("/users/:username/impersonate", utils.RequirePermission(policy.ImpersonateUsers), adminController.Impersonate)
+		admin.GET("/impersonations", utils.RequirePermission(policy.ImpersonateUsers), adminController.ListImpersonations)
+		admin.DELETE("/impersonations/:id", utils.RequirePermission(policy.ImpersonateUsers), adminController.EndImpersonation)
+		admin.GET("/audit-log", utils.RequirePermission(policy.ViewAuditLog), adminController.ListAuditLog)
+	}
+}
+
+// InvitationRoutes 注册方式与邀请码
+func InvitationRoutes(router *gin.Engine, InvitationService *service.InvitationService, AuthMiddleware *utils.AuthMiddleware) {
+	invitationController := &controller.InvitationController{InvitationService: InvitationService}
 	api := router.Group("/api")
 	{
-		api.DELETE("/articles/:slug/favorite", favoriteController.UnfavoriteArticle)
+		api.GET("/users/registration", invitationController.Registration)
+		api.POST("/user/invitations", AuthMiddleware.RequireAuth(), invitationController.CreateInvitation)
+		api.GET("/user/invitations", AuthMiddleware.RequireAuth(), invitationController.ListInvitations)
+		api.DELETE("/user/invitations/:id", AuthMiddleware.RequireAuth(), invitationController.RevokeInvitation)
+		api.GET("/admin/invitations", AuthMiddleware.RequireAuth(), utils.RequirePermission(policy.ManageInvitations), invitationController.ListAllInvitations)
 	}
 }
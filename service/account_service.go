@@ -0,0 +1,370 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goDemo/models"
+	"goDemo/policy"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// DeletedContentAnonymize 注销后保留文章和评论，作者改为匿名账号
+	DeletedContentAnonymize = "anonymize"
+	// DeletedContentDelete 注销后一并删除文章和评论
+	DeletedContentDelete = "delete"
+)
+
+var ErrDeletionNotScheduled = errors.New("账号没有申请注销")
+
+// AccountService 个人数据导出和账号注销
+type AccountService struct {
+	DB     *gorm.DB
+	Tokens *TokenService
+	// GracePeriod 申请注销到实际清理之间的时长，期间可以撤销
+	GracePeriod time.Duration
+	// DeletedContent 清理时如何处理用户发布的内容，取值见 DeletedContentAnonymize、DeletedContentDelete
+	DeletedContent string
+}
+
+// Export 汇总用户的个人数据
+func (s *AccountService) Export(user *models.UserModel) (*models.AccountExport, error) {
+	export := &models.AccountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: models.ExportProfile{
+			Username:            user.Username,
+			Email:               user.Email,
+			Bio:                 user.Bio,
+			Image:               user.Image,
+			Role:                user.Role,
+			EmailVerified:       user.EmailVerified(),
+			TwoFactorEnabled:    user.TwoFactorEnabled(),
+			CreatedAt:           user.CreatedAt,
+			DeletionScheduledAt: user.DeletionScheduledAt,
+		},
+		Articles:    []models.ExportArticle{},
+		Comments:    []models.ExportComment{},
+		Favorites:   []models.ExportArticleRef{},
+		Following:   []string{},
+		Followers:   []string{},
+		Sessions:    []models.SessionView{},
+		APIKeys:     []models.APIKeyView{},
+		Identities:  []models.IdentityView{},
+		Invitations: []models.InvitationView{},
+	}
+	if user.InvitedByID != nil {
+		var inviter models.UserModel
+		if err := s.DB.Unscoped().First(&inviter, *user.InvitedByID).Error; err == nil {
+			export.Profile.InvitedBy = inviter.Username
+		}
+	}
+
+	var articles []models.Article
+	if err := s.DB.Where("author_id = ?", user.ID).Order("created_at").Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	for _, article := range articles {
+		export.Articles = append(export.Articles, models.ExportArticle{
+			Slug:           article.Slug,
+			Title:          article.Title,
+			Description:    article.Description,
+			Body:           article.Body,
+			TagList:        article.TagList,
+			FavoritesCount: article.FavoritesCount,
+			Status:         article.Status,
+			PublishedAt:    article.PublishedAt,
+			CreatedAt:      article.CreatedAt,
+			UpdatedAt:      article.UpdatedAt,
+		})
+	}
+
+	err := s.DB.Table("comments").
+		Select("comments.id, articles.slug AS article_slug, articles.title AS article_title, comments.body, comments.created_at").
+		Joins("JOIN articles ON articles.id = comments.article_id").
+		Where("comments.author_id = ? AND comments.deleted_at IS NULL", user.ID).
+		Order("comments.created_at").
+		Scan(&export.Comments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Table("favorites").
+		Select("articles.slug, articles.title, favorites.created_at AS favorited_at").
+		Joins("JOIN articles ON articles.id = favorites.article_id AND articles.deleted_at IS NULL").
+		Where("favorites.user_id = ? AND favorites.deleted_at IS NULL", user.ID).
+		Order("favorites.created_at").
+		Scan(&export.Favorites).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Table("follows").
+		Joins("JOIN user_models ON user_models.id = follows.followed").
+		Where("follows.follower = ? AND follows.deleted_at IS NULL", user.ID).
+		Order("user_models.username").
+		Pluck("user_models.username", &export.Following).Error
+	if err != nil {
+		return nil, err
+	}
+	err = s.DB.Table("follows").
+		Joins("JOIN user_models ON user_models.id = follows.follower").
+		Where("follows.followed = ? AND follows.deleted_at IS NULL", user.ID).
+		Order("user_models.username").
+		Pluck("user_models.username", &export.Followers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.Tokens.ListSessions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		export.Sessions = append(export.Sessions, sessions[i].View(""))
+	}
+	// API 密钥包括已吊销和已过期的，只导出元数据
+	var keys []models.APIKey
+	if err := s.DB.Where("user_id = ?", user.ID).Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		export.APIKeys = append(export.APIKeys, keys[i].View())
+	}
+	var identities []models.UserIdentity
+	if err := s.DB.Where("user_id = ?", user.ID).Order("provider").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	for i := range identities {
+		export.Identities = append(export.Identities, identities[i].View())
+	}
+	var invitations []models.Invitation
+	err = s.DB.Preload("Invitees", func(db *gorm.DB) *gorm.DB { return db.Unscoped().Order("id") }).
+		Where("inviter_id = ?", user.ID).Order("id").Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range invitations {
+		invitations[i].Inviter = user
+		export.Invitations = append(export.Invitations, invitations[i].View(now))
+	}
+	return export, nil
+}
+
+// WriteArchive 把个人数据写成 zip：data.json 为完整数据，articles/ 下每篇文章一个 Markdown 文件，
+// comments.md 汇总发表过的评论
+func (s *AccountService) WriteArchive(w io.Writer, user *models.UserModel) error {
+	export, err := s.Export(user)
+	if err != nil {
+		return err
+	}
+	archive := zip.NewWriter(w)
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeArchiveFile(archive, "data.json", export.ExportedAt, data); err != nil {
+		return err
+	}
+	for _, article := range export.Articles {
+		name := fmt.Sprintf("articles/%s.md", article.Slug)
+		if err := writeArchiveFile(archive, name, article.UpdatedAt, []byte(articleMarkdown(article))); err != nil {
+			return err
+		}
+	}
+	if err := writeArchiveFile(archive, "comments.md", export.ExportedAt, []byte(commentsMarkdown(export.Comments))); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func writeArchiveFile(archive *zip.Writer, name string, modified time.Time, content []byte) error {
+	f, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	return err
+}
+
+// articleMarkdown 文章元数据写在 YAML front matter 中，正文保持原样
+func articleMarkdown(article models.ExportArticle) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %q\n", article.Title)
+	fmt.Fprintf(&b, "description: %q\n", article.Description)
+	fmt.Fprintf(&b, "slug: %s\n", article.Slug)
+	fmt.Fprintf(&b, "tags: [%s]\n", quoteAll(article.TagList))
+	fmt.Fprintf(&b, "favorites: %d\n", article.FavoritesCount)
+	fmt.Fprintf(&b, "status: %s\n", article.Status)
+	if article.PublishedAt != nil {
+		fmt.Fprintf(&b, "published: %s\n", article.PublishedAt.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "created: %s\n", article.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "updated: %s\n", article.UpdatedAt.UTC().Format(time.RFC3339))
+	b.WriteString("---\n\n")
+	b.WriteString(article.Body)
+	b.WriteString("\n")
+	return b.String()
+}
+
+func commentsMarkdown(comments []models.ExportComment) string {
+	var b strings.Builder
+	b.WriteString("# Comments\n")
+	for _, comment := range comments {
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n", comment.ArticleTitle, comment.ArticleSlug)
+		fmt.Fprintf(&b, "_%s_\n\n", comment.CreatedAt.UTC().Format(time.RFC3339))
+		b.WriteString(comment.Body)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// ScheduleDeletion 申请注销，到期前账号照常可用并可以撤销；重复申请不会推迟到期时间
+func (s *AccountService) ScheduleDeletion(user *models.UserModel) (time.Time, error) {
+	if user.DeletionScheduledAt != nil {
+		return *user.DeletionScheduledAt, nil
+	}
+	scheduledAt := time.Now().Add(s.GracePeriod)
+	if err := s.DB.Model(user).Update("deletion_scheduled_at", scheduledAt).Error; err != nil {
+		return time.Time{}, err
+	}
+	user.DeletionScheduledAt = &scheduledAt
+	return scheduledAt, nil
+}
+
+// CancelDeletion 撤销注销申请
+func (s *AccountService) CancelDeletion(user *models.UserModel) error {
+	if user.DeletionScheduledAt == nil {
+		return ErrDeletionNotScheduled
+	}
+	if err := s.DB.Model(user).Update("deletion_scheduled_at", nil).Error; err != nil {
+		return err
+	}
+	user.DeletionScheduledAt = nil
+	return nil
+}
+
+// PurgeDue 清理注销申请已到期的账号，返回清理的数量；单个账号失败不影响其他账号
+func (s *AccountService) PurgeDue(now time.Time) (int, error) {
+	var users []models.UserModel
+	if err := s.DB.Where("deletion_scheduled_at <= ?", now).Find(&users).Error; err != nil {
+		return 0, err
+	}
+	purged := 0
+	var errs []error
+	for i := range users {
+		if err := s.DB.Transaction(func(tx *gorm.DB) error { return s.purge(tx, &users[i]) }); err != nil {
+			errs = append(errs, fmt.Errorf("清理用户 %d 失败：%w", users[i].ID, err))
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+// RunPurger 按 interval 定期清理到期账号，在服务进程的后台 goroutine 中运行
+func (s *AccountService) RunPurger(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		purged, err := s.PurgeDue(now)
+		if err != nil {
+			log.Printf("清理注销账号出错：%v", err)
+		}
+		if purged > 0 {
+			log.Printf("已清理 %d 个注销账号", purged)
+		}
+	}
+}
+
+// purge 删除账号的个人数据。匿名化时保留用户行和发布的内容，用户名和邮箱替换为占位值；
+// 删除时连同文章（及其评论、收藏、历史版本、旧 slug 和标签关联）、评论、邀请码一起删除。关注关系、收藏和登录凭据两种方式都会删除
+func (s *AccountService) purge(tx *gorm.DB, user *models.UserModel) error {
+	if err := tx.Unscoped().Where("follower = ? OR followed = ?", user.ID, user.ID).Delete(&models.Follow{}).Error; err != nil {
+		return err
+	}
+	// 收藏计数只统计未删除的收藏
+	err := tx.Model(&models.Article{}).
+		Where("id IN (?)", tx.Model(&models.Favorite{}).Select("article_id").Where("user_id = ?", user.ID)).
+		Update("favorites_count", gorm.Expr("CASE WHEN favorites_count > 0 THEN favorites_count - 1 ELSE 0 END")).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Favorite{}).Error; err != nil {
+		return err
+	}
+	for _, model := range []interface{}{
+		&models.Session{}, &models.RefreshToken{}, &models.APIKey{}, &models.UserIdentity{},
+		&models.RecoveryCode{}, &models.PasswordResetToken{},
+	} {
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Unscoped().Where("link_user_id = ?", user.ID).Delete(&models.OIDCLoginState{}).Error; err != nil {
+		return err
+	}
+	// 邀请码不能再使用，匿名化时保留邀请记录
+	err = tx.Model(&models.Invitation{}).
+		Where("inviter_id = ? AND revoked_at IS NULL", user.ID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	if s.DeletedContent == DeletedContentDelete {
+		articleIDs := tx.Unscoped().Model(&models.Article{}).Select("id").Where("author_id = ?", user.ID)
+		if err := tx.Unscoped().Where("article_id IN (?)", articleIDs).Delete(&models.Favorite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("article_id IN (?) OR author_id = ?", articleIDs, user.ID).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		// 修改他人文章时留下的版本保留，修改人显示为空
+		if err := tx.Where("article_id IN (?)", articleIDs).Delete(&models.ArticleRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN (?)", articleIDs).Delete(&models.ArticleSlug{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN (?)", articleIDs).Delete(&models.ArticleTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("author_id = ?", user.ID).Delete(&models.Article{}).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&models.UserModel{}).Unscoped().
+			Where("invited_by_id = ?", user.ID).
+			Updates(map[string]interface{}{"invited_by_id": nil, "invitation_id": nil}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("inviter_id = ?", user.ID).Delete(&models.Invitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(user).Error
+	}
+
+	placeholder := fmt.Sprintf("deleted-%d", user.ID)
+	// 其余字段（密码、简介、两步验证等）一并清空
+	return tx.Model(user).Select("*").Omit("id", "created_at").Updates(&models.UserModel{
+		Username: placeholder,
+		Email:    placeholder + "@invalid",
+		Role:     policy.RoleUser,
+	}).Error
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"goDemo/models"
+	"goDemo/policy"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("API 密钥不存在")
+	ErrTooManyAPIKeys      = errors.New("API 密钥数量已达上限，请先吊销不再使用的密钥")
+	ErrInvalidAPIKeyExpiry = errors.New("过期时间必须晚于当前时间，且不能超过有效期上限")
+)
+
+// apiKeyPrefix 密钥原文的固定前缀，便于在代码仓库等位置扫描出泄露的密钥
+const apiKeyPrefix = "rw_"
+
+// apiKeyTouchInterval 最近使用时间的更新间隔，避免每个请求都写库
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService 管理个人 API 密钥
+type APIKeyService struct {
+	DB         *gorm.DB
+	MaxPerUser int
+	DefaultTTL time.Duration // 0 表示默认永不过期
+	MaxTTL     time.Duration // 0 表示不限制
+}
+
+// Create 创建 API 密钥，返回的原文只在此时可见
+func (s *APIKeyService) Create(user *models.UserModel, request *models.APIKeyRequest) (*models.APIKey, string, error) {
+	scopes, err := policy.ParseScopes(request.APIKey.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	expiresAt, err := s.expiry(now, request.APIKey.ExpiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := utils.RandomToken(24)
+	if err != nil {
+		return nil, "", err
+	}
+	rawKey := apiKeyPrefix + secret
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	key := &models.APIKey{
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(request.APIKey.Name),
+		Prefix:    rawKey[:len(apiKeyPrefix)+8],
+		KeyHash:   hashToken(rawKey),
+		Scopes:    strings.Join(names, ","),
+		ExpiresAt: expiresAt,
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := s.activeKeys(tx, user.ID, now).Model(&models.APIKey{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) >= s.MaxPerUser {
+			return ErrTooManyAPIKeys
+		}
+		return tx.Create(key).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return key, rawKey, nil
+}
+
+// expiry 计算过期时间，未指定时使用默认有效期
+func (s *APIKeyService) expiry(now time.Time, requested *time.Time) (*time.Time, error) {
+	if requested == nil {
+		if s.DefaultTTL == 0 {
+			return nil, nil
+		}
+		expiresAt := now.Add(s.DefaultTTL)
+		return &expiresAt, nil
+	}
+	if !requested.After(now) || (s.MaxTTL > 0 && requested.Sub(now) > s.MaxTTL) {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+	expiresAt := requested.UTC()
+	return &expiresAt, nil
+}
+
+// List 列出用户仍然有效的 API 密钥
+func (s *APIKeyService) List(user *models.UserModel) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := s.activeKeys(s.DB, user.ID, time.Now()).Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke 吊销用户自己的 API 密钥，立即生效
+func (s *APIKeyService) Revoke(user *models.UserModel, id uint) error {
+	result := s.DB.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, user.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ResolveAPIKey 按原文查找有效的 API 密钥并记录使用时间，供认证中间件调用
+func (s *APIKeyService) ResolveAPIKey(rawKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, nil
+	}
+	var key models.APIKey
+	err := s.DB.Where("key_hash = ?", hashToken(rawKey)).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, nil
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		// 条件更新，多个并发请求只有一个会写入
+		err := s.DB.Model(&models.APIKey{}).
+			Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", key.ID, now.Add(-apiKeyTouchInterval)).
+			Update("last_used_at", now).Error
+		if err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = &now
+	}
+	return &key, nil
+}
+
+func (s *APIKeyService) activeKeys(db *gorm.DB, userID uint, now time.Time) *gorm.DB {
+	return db.Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now)
+}
@@ -3,13 +3,44 @@ package service
 import (
 	"errors"
 	"github.com/gosimple/slug"
+	"goDemo/dialect"
 	"goDemo/models"
+	"goDemo/policy"
 	"gorm.io/gorm"
+	"log"
 	"time"
 )
 
 type ArticleService struct {
 	DB *gorm.DB
+	// Listener 文章发布后的通知，为 nil 时不通知
+	Listener PublishListener
+	// Verification 通过更新文章发布或定时发布时，与发布接口一样受邮箱验证限制
+	Verification policy.VerificationPolicy
+}
+
+// PublishListener 文章首次发布后调用，定时发布的文章在到期发布时才调用；
+// 取消发布后再次发布不会重复调用
+type PublishListener interface {
+	ArticlePublished(article *models.Article)
+}
+
+var (
+	ErrArticleNotFound         = errors.New("文章没找到哦")
+	ErrCommentNotFound         = errors.New("评论没找到哦")
+	ErrInvalidPublishAt        = errors.New("定时发布时间必须晚于当前时间")
+	ErrPublishAtWithStatus     = errors.New("定时发布时不能同时指定文章状态")
+	ErrArticleAlreadyPublished = errors.New("文章已发布，不能再定时发布")
+)
+
+// publishBatchSize 每次最多发布的定时文章数量，剩余的留到下一次
+const publishBatchSize = 100
+
+// UserArticlesParams 作者查看自己的文章，Status 为空时返回所有状态
+type UserArticlesParams struct {
+	Status string
+	Limit  int
+	Offset int
 }
 
 type ListArticlesParams struct {
@@ -26,12 +57,16 @@ type FeedArticlesParams struct {
 }
 
 func (s *ArticleService) ListArticles(userID uint, params ListArticlesParams) ([]models.Article, int64, error) {
-	query := s.DB.Model(&models.Article{}).Preload("Author").Order("created_at DESC")
-	if params.Tag != "" {
-		query = query.Where("tag_list @> ?", []string{params.Tag})
+	// 草稿、已归档和不公开列出的文章不出现在列表中
+	query := s.DB.Model(&models.Article{}).Preload("Author").
+		Where("articles.status = ?", models.ArticlePublished).
+		Order("articles.published_at DESC")
+	if tag := NormalizeTag(params.Tag); tag != "" {
+		query = query.Where("articles.id IN (?)", s.DB.Model(&models.ArticleTag{}).Select("article_tags.article_id").
+			Joins("JOIN tags ON tags.id = article_tags.tag_id").Where("tags.name = ?", tag))
 	}
 	if params.Author != "" {
-		query = query.Joins("JOIN authors ON articles.author_id = authors.id").Where("authors.username = ?", params.Author)
+		query = query.Joins("JOIN user_models AS authors ON articles.author_id = authors.id").Where("authors.username = ?", params.Author)
 	}
 	if params.Favorited != "" {
 		if userID == 0 {
@@ -79,7 +114,9 @@ func (s *ArticleService) FeedArticles(userID uint, params FeedArticlesParams) ([
 	query := s.DB.Model(&models.Article{}).
 		Preload("Author").
 		Where("author_id IN (?)", subQuery).
-		Where("deleted_at IS NULL")
+		Where("status = ?", models.ArticlePublished).
+		Where("deleted_at IS NULL").
+		Order("published_at DESC")
 
 	// 分页查询文章列表
 	err := query.Count(&count).Error
@@ -94,30 +131,106 @@ func (s *ArticleService) FeedArticles(userID uint, params FeedArticlesParams) ([
 	return articles, count, err
 }
 
-func (s *ArticleService) GetArticle(slug string) (*models.Article, error) {
-	var article models.Article
-	err := s.DB.Preload("Author").Where("slug = ?", slug).First(&article).Error
+// UserArticles 列出作者自己的文章，包括草稿，最近修改的在前
+func (s *ArticleService) UserArticles(actor *models.UserModel, params UserArticlesParams) ([]models.Article, int64, error) {
+	query := s.DB.Model(&models.Article{}).Preload("Author").Where("author_id = ?", actor.ID)
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+	var articles []models.Article
+	err := query.Order("updated_at DESC").Limit(params.Limit).Offset(params.Offset).Find(&articles).Error
+	return articles, total, err
+}
+
+// GetArticle 获取文章，actor 看不到的草稿按不存在处理。slug 是旧 slug 时返回的文章 slug 与参数不同
+func (s *ArticleService) GetArticle(actor *models.UserModel, slug string) (*models.Article, error) {
+	article, err := s.lookup(slug)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &article, nil
+	if !policy.CanViewArticle(actor, article) {
+		return nil, nil
+	}
+	if err := s.DB.Preload("Author").First(article, article.ID).Error; err != nil {
+		return nil, err
+	}
+	return article, nil
 }
 
-// CreateArticle 创建文章
-func (s *ArticleService) CreateArticle(userID uint, req models.CreateArticleRequest) (*models.Article, error) {
-	slug := GenerateSlug(req.Article.Title)
+// findArticle 按 slug（包括旧 slug）查找 actor 可以查看的文章，看不到的草稿返回 ErrArticleNotFound，不暴露草稿是否存在
+func (s *ArticleService) findArticle(actor *models.UserModel, slug string) (*models.Article, error) {
+	article, err := s.lookup(slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, err
+	}
+	if !policy.CanViewArticle(actor, article) {
+		return nil, ErrArticleNotFound
+	}
+	return article, nil
+}
+
+// CreateArticle 创建文章，指定了 slug 时使用并固定该 slug，否则根据标题生成不重复的 slug
+func (s *ArticleService) CreateArticle(actor *models.UserModel, req models.CreateArticleRequest) (*models.Article, error) {
+	if err := policy.Authorize(policy.CanCreateArticle(actor)); err != nil {
+		return nil, err
+	}
+	var custom string
+	if req.Article.Slug != "" {
+		var err error
+		if custom, err = NormalizeSlug(req.Article.Slug); err != nil {
+			return nil, err
+		}
+	}
+	tags, err := NormalizeTags(req.Article.TagList)
+	if err != nil {
+		return nil, err
+	}
 	article := models.Article{
-		Slug:        slug,
+		SlugPinned:  custom != "",
 		Title:       req.Article.Title,
 		Description: req.Article.Description,
 		Body:        req.Article.Body,
-		TagList:     req.Article.TagList,
-		AuthorID:    userID,
+		TagList:     tags,
+		AuthorID:    actor.ID,
 	}
-	err := s.DB.Create(&article).Error
+	firstPublish := false
+	if req.Article.PublishAt != nil {
+		if req.Article.Status != "" {
+			return nil, ErrPublishAtWithStatus
+		}
+		if err := schedule(&article, *req.Article.PublishAt, time.Now()); err != nil {
+			return nil, err
+		}
+	} else {
+		status := req.Article.Status
+		if status == "" {
+			status = models.ArticlePublished
+		}
+		firstPublish = setStatus(&article, status, time.Now())
+	}
+	// 标签关联和第一个版本与文章一起创建
+	err = s.saveWithSlug(&article, custom, true, func(tx *gorm.DB) error {
+		if err := tx.Create(&article).Error; err != nil {
+			return err
+		}
+		if err := setArticleTags(tx, article.ID, article.TagList); err != nil {
+			return err
+		}
+		return recordRevision(tx, &article, actor.ID, nil)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -126,24 +239,43 @@ func (s *ArticleService) CreateArticle(userID uint, req models.CreateArticleRequ
 	if err != nil {
 		return nil, err
 	}
+	if firstPublish {
+		s.notifyPublished(&article)
+	}
 	return &article, nil
 }
 
-// UpdateArticle 更新文章
-func (s *ArticleService) UpdateArticle(userID uint, slug string, req models.UpdateArticleRequest) (*models.Article, error) {
-	var article models.Article
-	//校验权限
-	err := s.DB.Where("slug = ? AND author_id=?", slug, userID).First(&article).Error
+// UpdateArticle 更新文章。修改标题时未固定的 slug 随标题重新生成，原 slug 继续指向该文章
+func (s *ArticleService) UpdateArticle(actor *models.UserModel, slug string, req models.UpdateArticleRequest) (*models.Article, error) {
+	article, err := s.findArticle(actor, slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("文章未找到或无权更新")
-		}
 		return nil, err
 	}
+	//校验权限
+	if err := policy.Authorize(policy.CanEditArticle(actor, article)); err != nil {
+		return nil, err
+	}
+	if req.Article.Status != nil && req.Article.PublishAt != nil {
+		return nil, ErrPublishAtWithStatus
+	}
+	publishing := req.Article.PublishAt != nil ||
+		(req.Article.Status != nil && *req.Article.Status == models.ArticlePublished && article.Status != models.ArticlePublished)
+	if publishing && !s.Verification.Allows(actor, policy.ActionCreateArticle) {
+		return nil, policy.ErrEmailNotVerified
+	}
+	var custom string
+	if req.Article.Slug != nil {
+		if *req.Article.Slug != "" {
+			if custom, err = NormalizeSlug(*req.Article.Slug); err != nil {
+				return nil, err
+			}
+		}
+		article.SlugPinned = custom != ""
+	}
+	before := *article
 	//更新文章字段
 	if req.Article.Title != nil {
 		article.Title = *req.Article.Title
-		article.Slug = GenerateSlug(*req.Article.Title)
 	}
 	if req.Article.Description != nil {
 		article.Description = *req.Article.Description
@@ -151,28 +283,181 @@ func (s *ArticleService) UpdateArticle(userID uint, slug string, req models.Upda
 	if req.Article.Body != nil {
 		article.Body = *req.Article.Body
 	}
-	err = s.DB.Save(&article).Error
+	firstPublish := false
+	if req.Article.Status != nil {
+		firstPublish = setStatus(article, *req.Article.Status, time.Now())
+	}
+	if req.Article.PublishAt != nil {
+		if err := schedule(article, *req.Article.PublishAt, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	// 内容有变化时追加一个版本，只修改状态不产生版本
+	changed := article.Title != before.Title || article.Description != before.Description || article.Body != before.Body
+	regenerate := !article.SlugPinned && article.Title != before.Title
+	err = s.saveWithSlug(article, custom, regenerate, func(tx *gorm.DB) error {
+		if err := tx.Save(article).Error; err != nil {
+			return err
+		}
+		if !changed {
+			return nil
+		}
+		return recordRevision(tx, article, actor.ID, nil)
+	})
 	if err != nil {
 		return nil, err
 	}
-	err = s.DB.Preload("Author").First(&article, "id=?", article.ID).Error
+	err = s.DB.Preload("Author").First(article, "id=?", article.ID).Error
 	if err != nil {
 		return nil, err
 	}
-	return &article, nil
+	if firstPublish {
+		s.notifyPublished(article)
+	}
+	return article, nil
 }
 
-// DeleteArticle 删除文章
-func (s *ArticleService) DeleteArticle(userID uint, slug string) error {
-	var article models.Article
-	err := s.DB.Where("slug = ? AND author_id=?", slug, userID).First(&article).Error
+// SetStatus 修改文章状态，用于发布和取消发布
+func (s *ArticleService) SetStatus(actor *models.UserModel, slug string, status string) (*models.Article, error) {
+	article, err := s.findArticle(actor, slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("文章未找到或无权删除")
+		return nil, err
+	}
+	if err := policy.Authorize(policy.CanEditArticle(actor, article)); err != nil {
+		return nil, err
+	}
+	firstPublish := false
+	if article.Status != status {
+		firstPublish = setStatus(article, status, time.Now())
+		err = s.DB.Model(article).Select("Status", "PublishedAt", "PublishAt").Updates(article).Error
+		if err != nil {
+			return nil, err
 		}
+	}
+	err = s.DB.Preload("Author").First(article, "id=?", article.ID).Error
+	if err != nil {
+		return nil, err
+	}
+	if firstPublish {
+		s.notifyPublished(article)
+	}
+	return article, nil
+}
+
+// PublishDue 发布到期的定时文章并发送通知，返回发布的数量。
+// 多个实例同时执行时，支持 SKIP LOCKED 的数据库上各实例领取不同的文章，
+// 带状态条件的更新保证每篇文章只被一个实例发布、只通知一次
+func (s *ArticleService) PublishDue(now time.Time) (int, error) {
+	published := 0
+	var firstPublished []models.Article
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		d, err := dialect.Of(tx)
+		if err != nil {
+			return err
+		}
+		var due []models.Article
+		err = tx.Clauses(d.SkipLocked()...).
+			Where("status = ? AND publish_at <= ?", models.ArticleScheduled, now).
+			Order("publish_at").Limit(publishBatchSize).Find(&due).Error
+		if err != nil {
+			return err
+		}
+		for _, article := range due {
+			result := tx.Model(&models.Article{}).
+				Where("id = ? AND status = ?", article.ID, models.ArticleScheduled).
+				Updates(map[string]interface{}{
+					"status":       models.ArticlePublished,
+					"publish_at":   nil,
+					"published_at": gorm.Expr("COALESCE(published_at, ?)", now),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 1 {
+				published++
+				// 取消发布后重新定时的文章已经通知过
+				if article.PublishedAt == nil {
+					firstPublished = append(firstPublished, article)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	// 提交之后再通知，避免通知了回滚的发布
+	for i := range firstPublished {
+		if err := s.DB.Preload("Author").First(&firstPublished[i], firstPublished[i].ID).Error; err != nil {
+			log.Printf("加载已发布文章 %d 失败，未发送通知：%v", firstPublished[i].ID, err)
+			continue
+		}
+		if s.Listener != nil {
+			s.Listener.ArticlePublished(&firstPublished[i])
+		}
+	}
+	return published, nil
+}
+
+// RunScheduler 按 interval 定期发布到期的定时文章，在服务进程的后台 goroutine 中运行
+func (s *ArticleService) RunScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		published, err := s.PublishDue(now)
+		if err != nil {
+			log.Printf("定时发布文章出错：%v", err)
+		}
+		if published > 0 {
+			log.Printf("已定时发布 %d 篇文章", published)
+		}
+	}
+}
+
+// notifyPublished 在后台发送发布通知，不阻塞请求
+func (s *ArticleService) notifyPublished(article *models.Article) {
+	if s.Listener == nil {
+		return
+	}
+	published := *article
+	go s.Listener.ArticlePublished(&published)
+}
+
+// setStatus 修改状态并取消定时发布，首次对外可见时记录发布时间，之后取消发布再发布时保持不变；
+// 返回是否为首次发布，只有首次发布才发送通知
+func setStatus(article *models.Article, status string, now time.Time) bool {
+	firstPublish := status == models.ArticlePublished && article.PublishedAt == nil
+	article.Status = status
+	article.PublishAt = nil
+	if status != models.ArticleDraft && article.PublishedAt == nil {
+		article.PublishedAt = &now
+	}
+	return firstPublish
+}
+
+// schedule 定时发布，已发布的文章需要先取消发布
+func schedule(article *models.Article, publishAt time.Time, now time.Time) error {
+	if !publishAt.After(now) {
+		return ErrInvalidPublishAt
+	}
+	if article.Status == models.ArticlePublished {
+		return ErrArticleAlreadyPublished
+	}
+	article.Status = models.ArticleScheduled
+	article.PublishAt = &publishAt
+	return nil
+}
+
+// DeleteArticle 删除文章
+func (s *ArticleService) DeleteArticle(actor *models.UserModel, slug string) error {
+	article, err := s.findArticle(actor, slug)
+	if err != nil {
+		return err
+	}
+	if err := policy.Authorize(policy.CanDeleteArticle(actor, article)); err != nil {
 		return err
 	}
-	err = s.DB.Delete(&article).Error
+	err = s.DB.Delete(article).Error
 	if err != nil {
 		return err
 	}
@@ -180,19 +465,18 @@ func (s *ArticleService) DeleteArticle(userID uint, slug string) error {
 }
 
 // CreateComment 创建评论
-func (s *ArticleService) CreateComment(userID uint, slug string, req models.CreateCommentRequest) (*models.Comment, error) {
-	var article models.Article
-	err := s.DB.Where("slug =?", slug).First(&article).Error
+func (s *ArticleService) CreateComment(actor *models.UserModel, slug string, req models.CreateCommentRequest) (*models.Comment, error) {
+	article, err := s.findArticle(actor, slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("文章未找到")
-		}
+		return nil, err
+	}
+	if err := policy.Authorize(policy.CanComment(actor, article)); err != nil {
 		return nil, err
 	}
 
 	comment := models.Comment{
 		Body:      req.Comment.Body,
-		AuthorID:  userID,
+		AuthorID:  actor.ID,
 		ArticleID: article.ID,
 	}
 
@@ -209,15 +493,15 @@ func (s *ArticleService) CreateComment(userID uint, slug string, req models.Crea
 }
 
 // GetCommentsBySlug 获取文章的评论列表
-func (s *ArticleService) GetCommentsBySlug(slug string, userID uint) ([]models.CommentResponse, error) {
-	var article models.Article
-	err := s.DB.Where("slug =?", slug).First(&article).Error
+func (s *ArticleService) GetCommentsBySlug(actor *models.UserModel, slug string) ([]models.CommentResponse, error) {
+	article, err := s.findArticle(actor, slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("文章没找到哦")
-		}
 		return nil, err
 	}
+	var userID uint
+	if actor != nil {
+		userID = actor.ID
+	}
 	var comments []models.Comment
 	err = s.DB.Preload("Author").Where("article_id = ?", article.ID).Find(&comments).Error
 	if err != nil {
@@ -266,24 +550,23 @@ func (s *ArticleService) GetCommentsBySlug(slug string, userID uint) ([]models.C
 }
 
 // DeleteComment 删除评论
-func (s *ArticleService) DeleteComment(userID uint, slug string, commentID uint) error {
+func (s *ArticleService) DeleteComment(actor *models.UserModel, slug string, commentID uint) error {
 	//定位文章评论
-	var article models.Article
-	err := s.DB.Where("slug =?", slug).First(&article).Error
+	article, err := s.findArticle(actor, slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("文章没找到哦")
-		}
 		return err
 	}
 	var Comment models.Comment
 	err = s.DB.Where("id =? AND article_id =?", commentID, article.ID).First(&Comment).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("评论没找到或无权删除")
+			return ErrCommentNotFound
 		}
 		return err
 	}
+	if err := policy.Authorize(policy.CanDeleteComment(actor, &Comment, article)); err != nil {
+		return err
+	}
 	err = s.DB.Delete(&Comment).Error
 	if err != nil {
 		return err
@@ -292,17 +575,17 @@ func (s *ArticleService) DeleteComment(userID uint, slug string, commentID uint)
 }
 
 // FavoriteArticle 添加文章收藏
-func (s *ArticleService) FavoriteArticle(userID uint, slug string) (*models.Article, error) {
-	var article models.Article
-	err := s.DB.Where("slug =?", slug).First(&article).Error
+func (s *ArticleService) FavoriteArticle(actor *models.UserModel, slug string) (*models.Article, error) {
+	found, err := s.findArticle(actor, slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("文章没找到哦")
-		}
+		return nil, err
+	}
+	article := *found
+	if err := policy.Authorize(policy.CanFavorite(actor, &article)); err != nil {
 		return nil, err
 	}
 	var favorite models.Favorite
-	err = s.DB.Where("user_id =? AND article_id =?", userID, article.ID).First(&favorite).Error
+	err = s.DB.Where("user_id =? AND article_id =?", actor.ID, article.ID).First(&favorite).Error
 	if err == nil {
 		// 已收藏，预加载作者信息并返回文章
 		err = s.DB.Preload("Author").Where("id = ?", article.ID).First(&article).Error
@@ -314,7 +597,7 @@ func (s *ArticleService) FavoriteArticle(userID uint, slug string) (*models.Arti
 		return nil, err
 	}
 	favorite = models.Favorite{
-		UserID:    userID,
+		UserID:    actor.ID,
 		ArticleID: article.ID,
 	}
 	err = s.DB.Create(&favorite).Error
@@ -335,17 +618,17 @@ func (s *ArticleService) FavoriteArticle(userID uint, slug string) (*models.Arti
 }
 
 // UnfavoriteArticle 取消文章收藏
-func (s *ArticleService) UnfavoriteArticle(userID uint, slug string) (*models.Article, error) {
-	var article models.Article
-	err := s.DB.Where("slug =?", slug).First(&article).Error
+func (s *ArticleService) UnfavoriteArticle(actor *models.UserModel, slug string) (*models.Article, error) {
+	found, err := s.findArticle(actor, slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("文章没找到哦")
-		}
-		return &article, err
+		return nil, err
+	}
+	article := *found
+	if err := policy.Authorize(policy.CanUnfavorite(actor, &article)); err != nil {
+		return nil, err
 	}
 	var favorite models.Favorite
-	err = s.DB.Where("user_id=? AND article_id =?", userID, article.ID).First(&favorite).Error
+	err = s.DB.Where("user_id=? AND article_id =?", actor.ID, article.ID).First(&favorite).Error
 	// 未收藏，直接返回
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = s.DB.Preload("Author").Where("id = ?", article.ID).First(&article).Error
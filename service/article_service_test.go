@@ -0,0 +1,329 @@
+package service
+
+import (
+	"errors"
+	"goDemo/models"
+	"goDemo/policy"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createArticle(t *testing.T, s *ArticleService, author *models.UserModel, title string, status string, tags ...string) *models.Article {
+	t.Helper()
+	var req models.CreateArticleRequest
+	req.Article.Title = title
+	req.Article.Description = "description"
+	req.Article.Body = "body"
+	req.Article.Status = status
+	req.Article.TagList = tags
+	article, err := s.CreateArticle(author, req)
+	if err != nil {
+		t.Fatalf("创建文章 %s 失败：%v", title, err)
+	}
+	return article
+}
+
+func slugsOf(articles []models.Article) []string {
+	slugs := []string{}
+	for _, article := range articles {
+		slugs = append(slugs, article.Slug)
+	}
+	return slugs
+}
+
+func TestListArticlesByTag(t *testing.T) {
+	db := newTestDB(t)
+	s := &ArticleService{DB: db}
+	alice := createUser(t, db, "alice")
+	bob := createUser(t, db, "bob")
+	createArticle(t, s, alice, "Go Basics", models.ArticlePublished, "Go", "Web Dev")
+	createArticle(t, s, bob, "Rust Basics", models.ArticlePublished, "rust", "web  dev")
+	createArticle(t, s, alice, "Go Draft", models.ArticleDraft, "go")
+	createArticle(t, s, alice, "Go Unlisted", models.ArticleUnlisted, "go")
+	deleted := createArticle(t, s, bob, "Go Deleted", models.ArticlePublished, "go")
+	if err := s.DeleteArticle(bob, deleted.Slug); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"go", []string{"go-basics"}},
+		{"GO", []string{"go-basics"}},
+		{"ＧＯ", []string{"go-basics"}},
+		{"web dev", []string{"rust-basics", "go-basics"}},
+		{" Web   Dev ", []string{"rust-basics", "go-basics"}},
+		{"rust", []string{"rust-basics"}},
+		{"web", []string{}},
+		{"missing", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			articles, total, err := s.ListArticles(0, ListArticlesParams{Tag: tt.tag})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := slugsOf(articles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListArticles(tag=%q) = %v, want %v", tt.tag, got, tt.want)
+			}
+			if int(total) != len(tt.want) {
+				t.Errorf("ListArticles(tag=%q) total = %d, want %d", tt.tag, total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestListArticlesByTagAndAuthor(t *testing.T) {
+	db := newTestDB(t)
+	s := &ArticleService{DB: db}
+	alice := createUser(t, db, "alice")
+	bob := createUser(t, db, "bob")
+	createArticle(t, s, alice, "Alice Go", models.ArticlePublished, "go")
+	createArticle(t, s, bob, "Bob Go", models.ArticlePublished, "go")
+
+	articles, total, err := s.ListArticles(0, ListArticlesParams{Tag: "go", Author: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := slugsOf(articles); total != 1 || !reflect.DeepEqual(got, []string{"bob-go"}) {
+		t.Errorf("ListArticles(tag=go, author=bob) = %v (%d), want [bob-go]", got, total)
+	}
+}
+
+func TestArticleTagListRoundTrip(t *testing.T) {
+	db := newTestDB(t)
+	s := &ArticleService{DB: db}
+	alice := createUser(t, db, "alice")
+	created := createArticle(t, s, alice, "Tags", models.ArticlePublished, " Zeta ", "alpha", "ZETA", "", "Ｂeta")
+
+	want := models.TagList{"zeta", "alpha", "beta"}
+	if !reflect.DeepEqual(created.TagList, want) {
+		t.Errorf("CreateArticle TagList = %v, want %v", created.TagList, want)
+	}
+	// 重新读取，确认 JSON 列按原顺序保存
+	var stored models.Article
+	if err := db.First(&stored, created.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored.TagList, want) {
+		t.Errorf("stored TagList = %v, want %v", stored.TagList, want)
+	}
+	var links int64
+	if err := db.Model(&models.ArticleTag{}).Where("article_id = ?", created.ID).Count(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+	if links != int64(len(want)) {
+		t.Errorf("article_tags rows = %d, want %d", links, len(want))
+	}
+
+	empty := createArticle(t, s, alice, "No Tags", models.ArticlePublished)
+	var untagged models.Article
+	if err := db.First(&untagged, empty.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(untagged.TagList) != 0 {
+		t.Errorf("TagList without tags = %v, want empty", untagged.TagList)
+	}
+}
+
+func TestPopularTags(t *testing.T) {
+	db := newTestDB(t)
+	s := &ArticleService{DB: db}
+	tags := &TagService{DB: db}
+	alice := createUser(t, db, "alice")
+	createArticle(t, s, alice, "One", models.ArticlePublished, "go", "rust")
+	createArticle(t, s, alice, "Two", models.ArticlePublished, "go")
+	createArticle(t, s, alice, "Three", models.ArticleDraft, "rust", "zig")
+
+	counts, err := tags.Popular(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 草稿中的标签不计数
+	want := []models.TagCount{{Tag: "go", ArticlesCount: 2}, {Tag: "rust", ArticlesCount: 1}}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("Popular() = %v, want %v", counts, want)
+	}
+}
+
+func TestCreateArticleRejectsLongTag(t *testing.T) {
+	db := newTestDB(t)
+	s := &ArticleService{DB: db}
+	alice := createUser(t, db, "alice")
+	var req models.CreateArticleRequest
+	req.Article.Title = "Long"
+	req.Article.Body = "body"
+	// 64 个中文字符没有超长，65 个超长
+	req.Article.TagList = []string{strings.Repeat("标", 64)}
+	if _, err := s.CreateArticle(alice, req); err != nil {
+		t.Errorf("CreateArticle with a 64 character tag = %v, want nil", err)
+	}
+	req.Article.Title = "Longer"
+	req.Article.TagList = []string{strings.Repeat("标", 65)}
+	if _, err := s.CreateArticle(alice, req); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("CreateArticle with a 65 character tag = %v, want ErrInvalidTag", err)
+	}
+}
+
+func TestUpdateArticlePublishRequiresVerifiedEmail(t *testing.T) {
+	restricted, err := policy.NewVerificationPolicy([]string{string(policy.ActionCreateArticle)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := models.ArticlePublished
+	unlisted := models.ArticleUnlisted
+	body := "new body"
+	publishAt := time.Now().Add(time.Hour)
+	tests := []struct {
+		name     string
+		verified bool
+		status   string
+		update   func(req *models.UpdateArticleRequest)
+		wantErr  error
+	}{
+		{"unverified publish", false, models.ArticleDraft, func(req *models.UpdateArticleRequest) { req.Article.Status = &published }, policy.ErrEmailNotVerified},
+		{"unverified schedule", false, models.ArticleDraft, func(req *models.UpdateArticleRequest) { req.Article.PublishAt = &publishAt }, policy.ErrEmailNotVerified},
+		{"unverified edit draft", false, models.ArticleDraft, func(req *models.UpdateArticleRequest) { req.Article.Body = &body }, nil},
+		{"unverified unlist draft", false, models.ArticleDraft, func(req *models.UpdateArticleRequest) { req.Article.Status = &unlisted }, nil},
+		{"unverified keep published", false, models.ArticlePublished, func(req *models.UpdateArticleRequest) { req.Article.Status = &published }, nil},
+		{"verified publish", true, models.ArticleDraft, func(req *models.UpdateArticleRequest) { req.Article.Status = &published }, nil},
+		{"verified schedule", true, models.ArticleDraft, func(req *models.UpdateArticleRequest) { req.Article.PublishAt = &publishAt }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			s := &ArticleService{DB: db, Verification: restricted}
+			author := createUser(t, db, "alice")
+			if !tt.verified {
+				if err := db.Model(author).Update("email_verified_at", nil).Error; err != nil {
+					t.Fatal(err)
+				}
+				author.EmailVerifiedAt = nil
+			}
+			article := createArticle(t, s, author, "Go Basics", tt.status)
+
+			var req models.UpdateArticleRequest
+			tt.update(&req)
+			_, err := s.UpdateArticle(author, article.Slug, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateArticle() error = %v, want %v", err, tt.wantErr)
+			}
+			var stored models.Article
+			if err := db.First(&stored, article.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != nil && stored.Status != tt.status {
+				t.Errorf("status = %s, want unchanged %s", stored.Status, tt.status)
+			}
+		})
+	}
+}
+
+// recordingListener 记录收到发布通知的文章 slug
+type recordingListener struct {
+	published chan string
+}
+
+func (l *recordingListener) ArticlePublished(article *models.Article) {
+	l.published <- article.Slug
+}
+
+// expectNotifications 等待异步发送的通知，want 为空时确认没有通知
+func expectNotifications(t *testing.T, l *recordingListener, want ...string) {
+	t.Helper()
+	for _, slug := range want {
+		select {
+		case got := <-l.published:
+			if got != slug {
+				t.Errorf("通知了 %s，want %s", got, slug)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("没有收到 %s 的发布通知", slug)
+		}
+	}
+	select {
+	case got := <-l.published:
+		t.Errorf("多余的发布通知：%s", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRepublishDoesNotNotifyAgain(t *testing.T) {
+	db := newTestDB(t)
+	listener := &recordingListener{published: make(chan string, 10)}
+	s := &ArticleService{DB: db, Listener: listener}
+	alice := createUser(t, db, "alice")
+
+	article := createArticle(t, s, alice, "Go Basics", models.ArticlePublished)
+	expectNotifications(t, listener, article.Slug)
+
+	// 取消发布后通过发布接口或更新文章再次发布，都不再通知
+	for i := 0; i < 2; i++ {
+		if _, err := s.SetStatus(alice, article.Slug, models.ArticleDraft); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			if _, err := s.SetStatus(alice, article.Slug, models.ArticlePublished); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			published := models.ArticlePublished
+			var req models.UpdateArticleRequest
+			req.Article.Status = &published
+			if _, err := s.UpdateArticle(alice, article.Slug, req); err != nil {
+				t.Fatal(err)
+			}
+		}
+		expectNotifications(t, listener)
+	}
+
+	// 草稿第一次发布时通知
+	draft := createArticle(t, s, alice, "Go Draft", models.ArticleDraft)
+	expectNotifications(t, listener)
+	if _, err := s.SetStatus(alice, draft.Slug, models.ArticlePublished); err != nil {
+		t.Fatal(err)
+	}
+	expectNotifications(t, listener, draft.Slug)
+}
+
+func TestPublishDueNotifiesFirstPublishOnly(t *testing.T) {
+	db := newTestDB(t)
+	listener := &recordingListener{published: make(chan string, 10)}
+	s := &ArticleService{DB: db, Listener: listener}
+	alice := createUser(t, db, "alice")
+	article := createArticle(t, s, alice, "Go Basics", models.ArticleDraft)
+
+	// 定时发布，然后把发布时间改到过去，模拟到期
+	publishDue := func() {
+		t.Helper()
+		publishAt := time.Now().Add(time.Hour)
+		var req models.UpdateArticleRequest
+		req.Article.PublishAt = &publishAt
+		if _, err := s.UpdateArticle(alice, article.Slug, req); err != nil {
+			t.Fatal(err)
+		}
+		err := db.Model(&models.Article{}).Where("id = ?", article.ID).Update("publish_at", time.Now().Add(-time.Minute)).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		count, err := s.PublishDue(time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("PublishDue() = %d, want 1", count)
+		}
+	}
+
+	publishDue()
+	expectNotifications(t, listener, article.Slug)
+
+	if _, err := s.SetStatus(alice, article.Slug, models.ArticleDraft); err != nil {
+		t.Fatal(err)
+	}
+	publishDue()
+	expectNotifications(t, listener)
+}
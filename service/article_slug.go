@@ -0,0 +1,170 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"goDemo/models"
+	"gorm.io/gorm"
+	"strings"
+)
+
+var (
+	ErrSlugTaken   = errors.New("slug 已被其他文章使用")
+	ErrInvalidSlug = errors.New("slug 至少要包含一个字母或数字，且不能超过 255 个字符")
+)
+
+const (
+	// maxSlugSuffix 标题生成的 slug 被占用时依次尝试 -2 到 -maxSlugSuffix，都被占用后改用随机短 ID
+	maxSlugSuffix = 10
+	// maxSlugBase 标题生成的 slug 的最大长度，给后缀留出位置
+	maxSlugBase = 200
+	// slugRetries 并发保存的文章抢到同一个 slug 时的最大尝试次数
+	slugRetries = 3
+)
+
+const slugIDAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// NormalizeSlug 规范化自定义 slug，规则与根据标题生成时相同
+func NormalizeSlug(value string) (string, error) {
+	normalized := GenerateSlug(value)
+	if normalized == "" || len(normalized) > 255 {
+		return "", ErrInvalidSlug
+	}
+	return normalized, nil
+}
+
+// lookup 按 slug 查找文章，slug 是文章用过的旧 slug 时返回该文章
+func (s *ArticleService) lookup(slug string) (*models.Article, error) {
+	var article models.Article
+	err := s.DB.Where("slug = ?", slug).First(&article).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = s.DB.Where("id = (?)", s.DB.Model(&models.ArticleSlug{}).Select("article_id").Where("slug = ?", slug)).
+			First(&article).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+// saveWithSlug 在事务中确定文章的 slug 后调用 save。custom 不为空时使用该自定义 slug（已规范化），
+// regenerate 为 true 时根据标题重新生成，两者都没有时 slug 不变。
+// 并发保存的文章抢到同一个 slug 时唯一索引冲突，重新确定 slug 后重试
+func (s *ArticleService) saveWithSlug(article *models.Article, custom string, regenerate bool, save func(tx *gorm.DB) error) error {
+	id, original := article.ID, article.Slug
+	var err error
+	for attempt := 1; attempt <= slugRetries; attempt++ {
+		article.ID, article.Slug = id, original
+		err = s.DB.Transaction(func(tx *gorm.DB) error {
+			if custom != "" || regenerate {
+				slug, err := resolveSlug(tx, article, custom)
+				if err != nil {
+					return err
+				}
+				if err := changeSlug(tx, article, slug); err != nil {
+					return err
+				}
+			}
+			return save(tx)
+		})
+		if err == nil || errors.Is(err, ErrSlugTaken) {
+			return err
+		}
+		// 失败不是因为 slug 被抢占时不重试
+		if taken, checkErr := slugTaken(s.DB, article.Slug, id); checkErr != nil || !taken {
+			return err
+		}
+	}
+	return err
+}
+
+// resolveSlug 确定文章要使用的 slug：自定义的 slug 被占用时返回 ErrSlugTaken，
+// 根据标题生成时自动加后缀避开已占用的 slug
+func resolveSlug(tx *gorm.DB, article *models.Article, custom string) (string, error) {
+	if custom != "" {
+		taken, err := slugTaken(tx, custom, article.ID)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", ErrSlugTaken
+		}
+		return custom, nil
+	}
+	base := GenerateSlug(article.Title)
+	if len(base) > maxSlugBase {
+		base = strings.TrimRight(base[:maxSlugBase], "-")
+	}
+	if base == "" {
+		base = "article"
+	}
+	candidate := base
+	for n := 2; n <= maxSlugSuffix+1; n++ {
+		taken, err := slugTaken(tx, candidate, article.ID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+	for {
+		id, err := shortID(6)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "-" + id
+		taken, err := slugTaken(tx, candidate, article.ID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+}
+
+// slugTaken 判断 slug 是否被 articleID 以外的文章占用。已删除文章的 slug 和其他文章的旧 slug 都算占用，
+// 以免旧链接指向别的文章；新文章的 articleID 为 0
+func slugTaken(tx *gorm.DB, slug string, articleID uint) (bool, error) {
+	var count int64
+	err := tx.Unscoped().Model(&models.Article{}).Where("slug = ? AND id <> ?", slug, articleID).Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	err = tx.Model(&models.ArticleSlug{}).Where("slug = ? AND article_id <> ?", slug, articleID).Count(&count).Error
+	return count > 0, err
+}
+
+// changeSlug 修改文章的 slug，原 slug 记入历史以便旧链接跳转；改回用过的 slug 时把它从历史中移除
+func changeSlug(tx *gorm.DB, article *models.Article, slug string) error {
+	if article.ID == 0 {
+		article.Slug = slug
+		return nil
+	}
+	if article.Slug == slug {
+		return nil
+	}
+	if err := tx.Where("slug = ? AND article_id = ?", slug, article.ID).Delete(&models.ArticleSlug{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Create(&models.ArticleSlug{Slug: article.Slug, ArticleID: article.ID}).Error; err != nil {
+		return err
+	}
+	article.Slug = slug
+	return nil
+}
+
+// shortID 生成由小写字母和数字组成的随机串
+func shortID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = slugIDAlphabet[int(b[i])%len(slugIDAlphabet)]
+	}
+	return string(b), nil
+}
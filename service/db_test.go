@@ -0,0 +1,46 @@
+package service
+
+import (
+	"fmt"
+	"goDemo/config"
+	"goDemo/migrations"
+	"goDemo/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newTestDB 为每个测试创建独立的 SQLite 内存数据库，通过与生产相同的方言和迁移建表
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", url.PathEscape(t.Name()))
+	db, err := config.InitDB(config.DatabaseConfig{Driver: "sqlite", DSN: config.Secret(dsn)})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败：%v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 内存数据库在最后一个连接关闭时销毁，只用一个连接避免共享缓存的表锁
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("执行迁移失败：%v", err)
+	}
+	return db
+}
+
+// createUser 直接写入一个邮箱已验证的普通用户
+func createUser(t *testing.T, db *gorm.DB, username string) *models.UserModel {
+	t.Helper()
+	now := time.Now()
+	user := &models.UserModel{Username: username, Email: username + "@example.com", Role: "user", EmailVerifiedAt: &now}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("创建用户 %s 失败：%v", username, err)
+	}
+	return user
+}
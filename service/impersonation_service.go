@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"goDemo/models"
+	"goDemo/policy"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrImpersonationNotFound = errors.New("代入记录不存在")
+	ErrImpersonationEnded    = errors.New("代入已结束")
+)
+
+// ImpersonationService 管理员代入用户身份排查问题，发起、结束和代入期间的写请求都记入审计日志
+type ImpersonationService struct {
+	DB     *gorm.DB
+	Auth   *utils.Auth
+	Tokens *TokenService
+	TTL    time.Duration
+}
+
+// AuditLogParams 审计日志筛选条件，actor、subject 为用户名
+type AuditLogParams struct {
+	Action  string
+	Actor   string
+	Subject string
+	Limit   int
+	Offset  int
+}
+
+// Start 代入 username 对应的用户，返回代入记录和代入令牌
+func (s *ImpersonationService) Start(actor *models.UserModel, username string, reason string, ip string) (*models.Impersonation, string, error) {
+	var subject models.UserModel
+	if err := s.DB.Where("username = ?", username).First(&subject).Error; err != nil {
+		return nil, "", err
+	}
+	if err := policy.Authorize(policy.CanImpersonate(actor, &subject)); err != nil {
+		return nil, "", err
+	}
+	jti, err := utils.RandomToken(16)
+	if err != nil {
+		return nil, "", err
+	}
+	impersonation := &models.Impersonation{
+		ActorID:         actor.ID,
+		ActorUsername:   actor.Username,
+		SubjectID:       subject.ID,
+		SubjectUsername: subject.Username,
+		Reason:          reason,
+		TokenID:         jti,
+		ExpiresAt:       time.Now().Add(s.TTL),
+	}
+	var token string
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(impersonation).Error; err != nil {
+			return err
+		}
+		var err error
+		token, err = s.Auth.GenerateImpersonationToken(&subject, actor, impersonation.ID, jti, impersonation.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			Action:          models.AuditImpersonationStart,
+			ActorID:         actor.ID,
+			ActorUsername:   actor.Username,
+			SubjectID:       subject.ID,
+			SubjectUsername: subject.Username,
+			ImpersonationID: &impersonation.ID,
+			IP:              ip,
+			Detail:          reason,
+		}).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return impersonation, token, nil
+}
+
+// End 提前结束代入并吊销代入令牌，任何拥有代入权限的管理员都可以结束
+func (s *ImpersonationService) End(actor *models.UserModel, id uint, ip string) (*models.Impersonation, error) {
+	var impersonation models.Impersonation
+	if err := s.DB.First(&impersonation, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImpersonationNotFound
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !impersonation.Active(now) {
+		return nil, ErrImpersonationEnded
+	}
+	if err := s.Tokens.Revoke(impersonation.TokenID, impersonation.ExpiresAt); err != nil {
+		return nil, err
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&impersonation).Update("ended_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			Action:          models.AuditImpersonationEnd,
+			ActorID:         actor.ID,
+			ActorUsername:   actor.Username,
+			SubjectID:       impersonation.SubjectID,
+			SubjectUsername: impersonation.SubjectUsername,
+			ImpersonationID: &impersonation.ID,
+			IP:              ip,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &impersonation, nil
+}
+
+// List 列出最近的代入记录，activeOnly 为 true 时只返回进行中的
+func (s *ImpersonationService) List(activeOnly bool) ([]models.Impersonation, error) {
+	query := s.DB.Order("id DESC").Limit(100)
+	if activeOnly {
+		query = query.Where("ended_at IS NULL AND expires_at > ?", time.Now())
+	}
+	var impersonations []models.Impersonation
+	err := query.Find(&impersonations).Error
+	return impersonations, err
+}
+
+// AuditLog 按条件查询审计日志，最新的在前
+func (s *ImpersonationService) AuditLog(params AuditLogParams) ([]models.AuditLog, int64, error) {
+	query := s.DB.Model(&models.AuditLog{})
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+	if params.Actor != "" {
+		query = query.Where("actor_username = ?", params.Actor)
+	}
+	if params.Subject != "" {
+		query = query.Where("subject_username = ?", params.Subject)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+	var entries []models.AuditLog
+	err := query.Order("id DESC").Limit(params.Limit).Offset(params.Offset).Find(&entries).Error
+	return entries, count, err
+}
+
+// RecordImpersonatedRequest 实现 utils.AuditRecorder
+func (s *ImpersonationService) RecordImpersonatedRequest(principal *utils.Principal, method string, path string, status int, ip string) error {
+	impersonationID := principal.Claims.ImpersonationID
+	return s.DB.Create(&models.AuditLog{
+		Action:          models.AuditImpersonatedRequest,
+		ActorID:         principal.Actor.ID,
+		ActorUsername:   principal.Actor.Username,
+		SubjectID:       principal.User.ID,
+		SubjectUsername: principal.User.Username,
+		ImpersonationID: &impersonationID,
+		Method:          method,
+		Path:            truncate(path, 255),
+		Status:          status,
+		IP:              ip,
+	}).Error
+}
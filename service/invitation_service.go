@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"goDemo/models"
+	"goDemo/policy"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// 注册方式
+const (
+	RegistrationOpen       = "open"
+	RegistrationInviteOnly = "invite_only"
+	RegistrationClosed     = "closed"
+)
+
+var (
+	ErrRegistrationClosed      = errors.New("暂不开放注册")
+	ErrInvitationRequired      = errors.New("注册需要邀请码")
+	ErrInvalidInvitation       = errors.New("邀请码无效、已过期或已用完")
+	ErrInvitationNotFound      = errors.New("邀请码不存在")
+	ErrInvitationsDisabled     = errors.New("只有管理员可以创建邀请码")
+	ErrTooManyInvitations      = errors.New("有效的邀请码数量已达上限，请先吊销不再使用的邀请码")
+	ErrInvalidInvitationExpiry = errors.New("过期时间必须晚于当前时间，且不能超过有效期上限")
+	ErrInvitationMaxUses       = errors.New("邀请码的使用次数超过上限")
+)
+
+// InvitationService 注册方式与邀请码
+type InvitationService struct {
+	DB   *gorm.DB
+	Mode string
+	// TTL 邀请码默认有效期，也是普通用户可设置的最长有效期，0 表示永不过期
+	TTL time.Duration
+	// UserLimit 普通用户同时有效的邀请码数量，0 表示只有管理员可以创建
+	UserLimit int
+	// UserMaxUses 普通用户创建的邀请码最多可使用的次数
+	UserMaxUses int
+}
+
+// Registration 返回当前的注册方式
+func (s *InvitationService) Registration() models.RegistrationResponse {
+	return models.RegistrationResponse{Mode: s.Mode, InvitationRequired: s.Mode == RegistrationInviteOnly}
+}
+
+// OpenRegistration 是否允许不填邀请码注册，第三方登录只在此时自动创建账号
+func (s *InvitationService) OpenRegistration() bool {
+	return s.Mode == RegistrationOpen
+}
+
+// CheckRegistration 按注册方式检查是否允许注册，code 为用户填写的邀请码
+func (s *InvitationService) CheckRegistration(code string) error {
+	switch {
+	case s.Mode == RegistrationClosed:
+		return ErrRegistrationClosed
+	case s.Mode == RegistrationInviteOnly && strings.TrimSpace(code) == "":
+		return ErrInvitationRequired
+	}
+	return nil
+}
+
+// Redeem 在注册事务中使用一次邀请码，并在 user 上记录邀请人，需要在创建用户之前调用
+func (s *InvitationService) Redeem(tx *gorm.DB, code string, user *models.UserModel) error {
+	var invitation models.Invitation
+	err := tx.Where("code_hash = ?", hashToken(normalizeInvitationCode(code))).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidInvitation
+		}
+		return err
+	}
+	// 条件更新保证并发注册时不会超过使用次数
+	now := time.Now()
+	result := tx.Model(&models.Invitation{}).
+		Where("id = ? AND use_count < max_uses AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", invitation.ID, now).
+		Update("use_count", gorm.Expr("use_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidInvitation
+	}
+	user.InvitedByID = invitation.InviterID
+	user.InvitationID = &invitation.ID
+	return nil
+}
+
+// Create 创建邀请码，返回的原文只在此时可见。拥有邀请码管理权限的用户不受数量、次数和有效期上限的限制，
+// actor 为 nil 表示通过 invite 子命令创建，同样不受限制
+func (s *InvitationService) Create(actor *models.UserModel, request *models.InvitationRequest) (*models.Invitation, string, error) {
+	unlimited := actor == nil || policy.Can(actor, policy.ManageInvitations)
+	if !unlimited && s.UserLimit == 0 {
+		return nil, "", ErrInvitationsDisabled
+	}
+	maxUses := request.Invitation.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+	if !unlimited && maxUses > s.UserMaxUses {
+		return nil, "", ErrInvitationMaxUses
+	}
+	now := time.Now()
+	expiresAt, err := s.expiry(now, request.Invitation.ExpiresAt, unlimited)
+	if err != nil {
+		return nil, "", err
+	}
+	code, err := utils.RandomToken(10)
+	if err != nil {
+		return nil, "", err
+	}
+	invitation := &models.Invitation{
+		CodeHash:  hashToken(code),
+		Prefix:    code[:6],
+		Note:      strings.TrimSpace(request.Invitation.Note),
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+	}
+	if actor != nil {
+		invitation.InviterID = &actor.ID
+		invitation.Inviter = actor
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if !unlimited {
+			var count int64
+			if err := s.activeInvitations(tx, now).Model(&models.Invitation{}).Where("inviter_id = ?", actor.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if int(count) >= s.UserLimit {
+				return ErrTooManyInvitations
+			}
+		}
+		return tx.Omit("Inviter").Create(invitation).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return invitation, code, nil
+}
+
+// expiry 计算过期时间，未指定时使用默认有效期
+func (s *InvitationService) expiry(now time.Time, requested *time.Time, unlimited bool) (*time.Time, error) {
+	if requested == nil {
+		if s.TTL == 0 {
+			return nil, nil
+		}
+		expiresAt := now.Add(s.TTL)
+		return &expiresAt, nil
+	}
+	if !requested.After(now) || (!unlimited && s.TTL > 0 && requested.After(now.Add(s.TTL))) {
+		return nil, ErrInvalidInvitationExpiry
+	}
+	return requested, nil
+}
+
+// ListByInviter 列出用户创建的邀请码，包括已失效的，以便查看邀请了哪些人
+func (s *InvitationService) ListByInviter(user *models.UserModel) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	err := s.withUsers(s.DB).Where("inviter_id = ?", user.ID).Order("id DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// List 列出所有邀请码，activeOnly 为 true 时只返回仍可使用的
+func (s *InvitationService) List(activeOnly bool, limit int, offset int) ([]models.Invitation, int64, error) {
+	query := s.DB.Model(&models.Invitation{})
+	if activeOnly {
+		query = s.activeInvitations(query, time.Now())
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	var invitations []models.Invitation
+	err := s.withUsers(query).Order("id DESC").Limit(limit).Offset(offset).Find(&invitations).Error
+	return invitations, count, err
+}
+
+// Revoke 吊销邀请码，已使用该邀请码注册的账号不受影响
+func (s *InvitationService) Revoke(actor *models.UserModel, id uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := s.withUsers(s.DB).First(&invitation, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	if !policy.CanRevokeInvitation(actor, &invitation) {
+		// 不暴露他人邀请码是否存在
+		return nil, ErrInvitationNotFound
+	}
+	if invitation.RevokedAt == nil {
+		now := time.Now()
+		if err := s.DB.Model(&invitation).Update("revoked_at", now).Error; err != nil {
+			return nil, err
+		}
+		invitation.RevokedAt = &now
+	}
+	return &invitation, nil
+}
+
+func (s *InvitationService) activeInvitations(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("revoked_at IS NULL AND use_count < max_uses AND (expires_at IS NULL OR expires_at > ?)", now)
+}
+
+// withUsers 预加载邀请人和被邀请人，已注销的账号也要能显示
+func (s *InvitationService) withUsers(db *gorm.DB) *gorm.DB {
+	return db.Preload("Inviter", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Invitees", func(db *gorm.DB) *gorm.DB { return db.Unscoped().Order("id") })
+}
+
+// normalizeInvitationCode 邀请码不区分大小写，忽略首尾空白
+func normalizeInvitationCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
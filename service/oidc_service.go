@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"goDemo/models"
+	"goDemo/oidc"
+	"goDemo/policy"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrUnknownProvider    = errors.New("未配置该登录方式")
+	ErrInvalidOIDCState   = errors.New("登录请求无效或已过期，请重新发起")
+	ErrIdentityLinked     = errors.New("该第三方账号已关联其他用户")
+	ErrOIDCEmailTaken     = errors.New("该邮箱已注册，请先用密码登录，再在账号设置中关联第三方账号")
+	ErrOIDCEmailMissing   = errors.New("身份提供方没有返回邮箱，无法创建账号")
+	ErrIdentityNotFound   = errors.New("未关联该第三方账号")
+	ErrLastSignInMethod   = errors.New("这是账号唯一的登录方式，请先设置密码再解除关联")
+	ErrProviderAlreadySet = errors.New("已关联该登录方式的其他账号，请先解除关联")
+	ErrOIDCSignupDisabled = errors.New("暂不开放注册，只能使用已关联的账号登录")
+)
+
+// OIDCService 通过 OpenID Connect 身份提供方登录、注册和关联账号
+type OIDCService struct {
+	DB        *gorm.DB
+	Providers map[string]*oidc.Provider
+	StateTTL  time.Duration
+	// Invitations 只有开放注册时才为首次登录的身份创建账号，第三方登录无法填写邀请码
+	Invitations *InvitationService
+}
+
+// OIDCResult 回调处理结果，Linked 为 true 表示为已登录用户关联了身份，不需要签发令牌
+type OIDCResult struct {
+	User   *models.UserModel
+	Linked bool
+}
+
+// ListProviders 返回已配置的登录方式
+func (s *OIDCService) ListProviders() []models.OIDCProviderView {
+	views := []models.OIDCProviderView{}
+	for _, p := range oidc.Sorted(s.Providers) {
+		views = append(views, models.OIDCProviderView{Name: p.Name, DisplayName: p.DisplayName})
+	}
+	return views
+}
+
+// Authorize 生成 state、nonce 和 PKCE 校验值，返回跳转到身份提供方的地址
+// linkUser 不为空时，回调后把身份关联到该用户而不是登录
+func (s *OIDCService) Authorize(ctx context.Context, providerName string, linkUser *models.UserModel) (string, error) {
+	provider, ok := s.Providers[providerName]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+	state, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return "", err
+	}
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	// 顺带清理过期的登录请求
+	if err := s.DB.Unscoped().Where("expires_at < ?", now).Delete(&models.OIDCLoginState{}).Error; err != nil {
+		return "", err
+	}
+	loginState := &models.OIDCLoginState{
+		StateHash:    hashToken(state),
+		Provider:     provider.Name,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    now.Add(s.StateTTL),
+	}
+	if linkUser != nil {
+		loginState.LinkUserID = &linkUser.ID
+	}
+	if err := s.DB.Create(loginState).Error; err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// Callback 校验 state，用授权码换取并校验 ID Token，然后登录、创建或关联账号
+func (s *OIDCService) Callback(ctx context.Context, providerName string, code string, state string) (*OIDCResult, error) {
+	provider, ok := s.Providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	loginState, err := s.consumeState(provider.Name, state)
+	if err != nil {
+		return nil, err
+	}
+	rawIDToken, err := provider.Exchange(ctx, code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := provider.VerifyIDToken(ctx, rawIDToken, loginState.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OIDCResult{Linked: loginState.LinkUserID != nil}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var identity models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider.Name, claims.Subject).First(&identity).Error
+		switch {
+		case err == nil:
+			if loginState.LinkUserID != nil && identity.UserID != *loginState.LinkUserID {
+				return ErrIdentityLinked
+			}
+			result.User, err = s.touchIdentity(tx, &identity, claims)
+			return err
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		case loginState.LinkUserID != nil:
+			var user models.UserModel
+			if err := tx.First(&user, *loginState.LinkUserID).Error; err != nil {
+				return err
+			}
+			result.User = &user
+		default:
+			result.User, err = s.userForNewIdentity(tx, provider, claims)
+			if err != nil {
+				return err
+			}
+		}
+		return s.link(tx, result.User, provider.Name, claims)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListIdentities 列出用户已关联的第三方身份
+func (s *OIDCService) ListIdentities(user *models.UserModel) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := s.DB.Where("user_id = ?", user.ID).Order("provider").Find(&identities).Error
+	return identities, err
+}
+
+// Unlink 解除关联，没有密码的账号不能解除最后一个身份，否则将无法登录
+func (s *OIDCService) Unlink(user *models.UserModel, providerName string) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		var identities []models.UserIdentity
+		if err := tx.Where("user_id = ?", user.ID).Find(&identities).Error; err != nil {
+			return err
+		}
+		var target *models.UserIdentity
+		for i := range identities {
+			if identities[i].Provider == providerName {
+				target = &identities[i]
+			}
+		}
+		if target == nil {
+			return ErrIdentityNotFound
+		}
+		if !user.HasPassword() && len(identities) == 1 {
+			return ErrLastSignInMethod
+		}
+		return tx.Unscoped().Delete(target).Error
+	})
+}
+
+// consumeState 取回并作废登录请求，每个 state 只能使用一次
+func (s *OIDCService) consumeState(providerName string, state string) (*models.OIDCLoginState, error) {
+	var loginState models.OIDCLoginState
+	err := s.DB.Where("state_hash = ? AND provider = ?", hashToken(state), providerName).First(&loginState).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+	if loginState.UsedAt != nil || time.Now().After(loginState.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+	result := s.DB.Model(&models.OIDCLoginState{}).
+		Where("id = ? AND used_at IS NULL", loginState.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidOIDCState
+	}
+	return &loginState, nil
+}
+
+// touchIdentity 已关联的身份再次登录，记录登录时间和最新邮箱
+func (s *OIDCService) touchIdentity(tx *gorm.DB, identity *models.UserIdentity, claims *oidc.Claims) (*models.UserModel, error) {
+	var user models.UserModel
+	if err := tx.First(&user, identity.UserID).Error; err != nil {
+		return nil, err
+	}
+	err := tx.Model(identity).Updates(map[string]interface{}{"email": claims.Email, "last_login_at": time.Now()}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// userForNewIdentity 首次使用该身份登录：按配置关联同邮箱的已有账号，或者创建新账号
+func (s *OIDCService) userForNewIdentity(tx *gorm.DB, provider *oidc.Provider, claims *oidc.Claims) (*models.UserModel, error) {
+	if claims.Email == "" {
+		return nil, ErrOIDCEmailMissing
+	}
+	var user models.UserModel
+	err := tx.Where("email = ?", claims.Email).First(&user).Error
+	if err == nil {
+		if !provider.LinkVerifiedEmail || !claims.EmailVerified {
+			return nil, ErrOIDCEmailTaken
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if !s.Invitations.OpenRegistration() {
+		return nil, ErrOIDCSignupDisabled
+	}
+	username, err := s.availableUsername(tx, claims)
+	if err != nil {
+		return nil, err
+	}
+	// 第三方登录创建的账号没有密码，只能通过身份提供方登录，之后可以通过找回密码设置
+	user = models.UserModel{
+		Username: username,
+		Email:    claims.Email,
+		Image:    truncate(claims.Picture, 255),
+		Role:     policy.RoleUser,
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// link 创建身份关联，同一用户在同一提供方只能关联一个身份
+func (s *OIDCService) link(tx *gorm.DB, user *models.UserModel, providerName string, claims *oidc.Claims) error {
+	var count int64
+	err := tx.Model(&models.UserIdentity{}).Where("user_id = ? AND provider = ?", user.ID, providerName).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrProviderAlreadySet
+	}
+	now := time.Now()
+	return tx.Create(&models.UserIdentity{
+		UserID:      user.ID,
+		Provider:    providerName,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+	}).Error
+}
+
+// availableUsername 依次取 preferred_username、nickname、name、邮箱前缀作为用户名，重名时追加数字
+func (s *OIDCService) availableUsername(tx *gorm.DB, claims *oidc.Claims) (string, error) {
+	base := ""
+	for _, candidate := range []string{claims.PreferredUsername, claims.Nickname, claims.Name, strings.Split(claims.Email, "@")[0]} {
+		if base = sanitizeUsername(candidate); base != "" {
+			break
+		}
+	}
+	if base == "" {
+		base = "user"
+	}
+	for i := 0; i < 20; i++ {
+		username := base
+		if i > 0 {
+			suffix, err := utils.RandomToken(2)
+			if err != nil {
+				return "", err
+			}
+			username = fmt.Sprintf("%s-%s", base, suffix)
+		}
+		var count int64
+		if err := tx.Model(&models.UserModel{}).Unscoped().Where("username = ?", username).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return username, nil
+		}
+	}
+	return "", errors.New("无法生成可用的用户名")
+}
+
+// sanitizeUsername 只保留字母、数字、下划线和连字符，最长 32 个字符
+func sanitizeUsername(value string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(value) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-':
+			b.WriteRune(r)
+		case r == ' ' || r == '.':
+			b.WriteRune('-')
+		}
+	}
+	return truncate(strings.Trim(b.String(), "-"), 32)
+}
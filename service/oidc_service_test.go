@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"goDemo/config"
+	"goDemo/models"
+	"goDemo/oidc"
+	"goDemo/test/mockoidc/idp"
+	"gorm.io/gorm"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newOIDCService 启动模拟身份提供方，返回配置了该提供方（名称为 mock）的服务
+func newOIDCService(t *testing.T, db *gorm.DB, linkVerifiedEmail bool, registration string) (*OIDCService, *idp.Server) {
+	t.Helper()
+	mock, err := idp.New("", "realworld", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	mock.Issuer = server.URL
+	provider := oidc.New(config.OIDCProviderConfig{
+		Name:              "mock",
+		Issuer:            server.URL,
+		ClientID:          "realworld",
+		LinkVerifiedEmail: linkVerifiedEmail,
+	}, "http://realworld.test")
+	return &OIDCService{
+		DB:          db,
+		Providers:   map[string]*oidc.Provider{provider.Name: provider},
+		StateTTL:    10 * time.Minute,
+		Invitations: &InvitationService{DB: db, Mode: registration},
+	}, mock
+}
+
+// oidcLogin 发起登录并在模拟身份提供方以 params 描述的身份完成授权，返回回调参数
+func oidcLogin(t *testing.T, s *OIDCService, mock *idp.Server, linkUser *models.UserModel, params url.Values) (string, string) {
+	t.Helper()
+	authURL, err := s.Authorize(context.Background(), "mock", linkUser)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	code, state, err := mock.Login(authURL, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, state
+}
+
+func TestOIDCCallbackCreatesUser(t *testing.T) {
+	db := newTestDB(t)
+	s, mock := newOIDCService(t, db, false, RegistrationOpen)
+
+	code, state := oidcLogin(t, s, mock, nil, url.Values{"sub": {"sub-1"}, "email": {"new@example.com"}, "preferred_username": {"newbie"}})
+	result, err := s.Callback(context.Background(), "mock", code, state)
+	if err != nil {
+		t.Fatalf("Callback() error = %v", err)
+	}
+	if result.Linked || result.User.Username != "newbie" || result.User.Email != "new@example.com" {
+		t.Errorf("Callback() = %+v, want new user newbie", result)
+	}
+	if result.User.EmailVerifiedAt == nil {
+		t.Error("邮箱已由身份提供方验证，新账号应标记为已验证")
+	}
+
+	// 再次登录使用已关联的身份，不会重复创建账号
+	code, state = oidcLogin(t, s, mock, nil, url.Values{"sub": {"sub-1"}, "email": {"new@example.com"}})
+	again, err := s.Callback(context.Background(), "mock", code, state)
+	if err != nil {
+		t.Fatalf("second Callback() error = %v", err)
+	}
+	if again.User.ID != result.User.ID {
+		t.Errorf("second Callback() user = %d, want %d", again.User.ID, result.User.ID)
+	}
+}
+
+func TestOIDCCallbackStateUsedOnce(t *testing.T) {
+	db := newTestDB(t)
+	s, mock := newOIDCService(t, db, false, RegistrationOpen)
+
+	code, state := oidcLogin(t, s, mock, nil, url.Values{"sub": {"sub-1"}})
+	if _, err := s.Callback(context.Background(), "mock", code, state); err != nil {
+		t.Fatalf("Callback() error = %v", err)
+	}
+	// 即使身份提供方重新签发了授权码，已使用的 state 也不能再用
+	authURL, err := s.Providers["mock"].AuthCodeURL(context.Background(), state, "nonce", "challenge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _, err = mock.Login(authURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Callback(context.Background(), "mock", code, state); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("reused state Callback() error = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCCallbackStateExpired(t *testing.T) {
+	db := newTestDB(t)
+	s, mock := newOIDCService(t, db, false, RegistrationOpen)
+
+	code, state := oidcLogin(t, s, mock, nil, url.Values{"sub": {"sub-1"}})
+	err := db.Model(&models.OIDCLoginState{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Second)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Callback(context.Background(), "mock", code, state); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("Callback() error = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCCallbackUnknownState(t *testing.T) {
+	db := newTestDB(t)
+	s, mock := newOIDCService(t, db, false, RegistrationOpen)
+
+	code, _ := oidcLogin(t, s, mock, nil, url.Values{"sub": {"sub-1"}})
+	if _, err := s.Callback(context.Background(), "mock", code, "forged"); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("Callback() error = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCCallbackNonceMismatch(t *testing.T) {
+	db := newTestDB(t)
+	s, mock := newOIDCService(t, db, false, RegistrationOpen)
+
+	// 身份提供方签发的 ID Token 中的 nonce 与发起登录时保存的不一致，例如令牌被从其他登录请求中截取
+	code, state := oidcLogin(t, s, mock, nil, url.Values{"sub": {"sub-1"}, "nonce": {"another-nonce"}})
+	if _, err := s.Callback(context.Background(), "mock", code, state); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("Callback() error = %v, want ErrInvalidIDToken", err)
+	}
+	var count int64
+	db.Model(&models.UserIdentity{}).Count(&count)
+	if count != 0 {
+		t.Errorf("identities = %d, want 0", count)
+	}
+}
+
+func TestOIDCCallbackExistingEmail(t *testing.T) {
+	tests := []struct {
+		name              string
+		linkVerifiedEmail bool
+		emailVerified     string
+		wantErr           error
+	}{
+		{"link verified email", true, "true", nil},
+		{"email not verified", true, "false", ErrOIDCEmailTaken},
+		{"linking disabled", false, "true", ErrOIDCEmailTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			alice := createUser(t, db, "alice")
+			s, mock := newOIDCService(t, db, tt.linkVerifiedEmail, RegistrationOpen)
+
+			code, state := oidcLogin(t, s, mock, nil, url.Values{"sub": {"sub-1"}, "email": {alice.Email}, "email_verified": {tt.emailVerified}})
+			result, err := s.Callback(context.Background(), "mock", code, state)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Callback() error = %v, want %v", err, tt.wantErr)
+			}
+			var identities []models.UserIdentity
+			if err := db.Find(&identities).Error; err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != nil {
+				if len(identities) != 0 {
+					t.Errorf("identities = %d, want 0", len(identities))
+				}
+				return
+			}
+			if result.User.ID != alice.ID {
+				t.Errorf("Callback() user = %d, want alice (%d)", result.User.ID, alice.ID)
+			}
+			if len(identities) != 1 || identities[0].UserID != alice.ID || identities[0].Subject != "sub-1" {
+				t.Errorf("identities = %+v, want sub-1 linked to alice", identities)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackSignupDisabled(t *testing.T) {
+	db := newTestDB(t)
+	s, mock := newOIDCService(t, db, false, RegistrationInviteOnly)
+
+	code, state := oidcLogin(t, s, mock, nil, url.Values{"sub": {"sub-1"}, "email": {"new@example.com"}})
+	if _, err := s.Callback(context.Background(), "mock", code, state); !errors.Is(err, ErrOIDCSignupDisabled) {
+		t.Errorf("Callback() error = %v, want ErrOIDCSignupDisabled", err)
+	}
+	var count int64
+	db.Model(&models.UserModel{}).Where("email = ?", "new@example.com").Count(&count)
+	if count != 0 {
+		t.Errorf("users = %d, want 0", count)
+	}
+
+	// 只邀请注册时，已关联身份的账号仍然可以登录
+	bob := createUser(t, db, "bob")
+	code, state = oidcLogin(t, s, mock, bob, url.Values{"sub": {"sub-2"}})
+	if _, err := s.Callback(context.Background(), "mock", code, state); err != nil {
+		t.Fatalf("link Callback() error = %v", err)
+	}
+	code, state = oidcLogin(t, s, mock, nil, url.Values{"sub": {"sub-2"}})
+	result, err := s.Callback(context.Background(), "mock", code, state)
+	if err != nil {
+		t.Fatalf("Callback() error = %v", err)
+	}
+	if result.User.ID != bob.ID {
+		t.Errorf("Callback() user = %d, want bob (%d)", result.User.ID, bob.ID)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"goDemo/mail"
+	"goDemo/models"
+	"goDemo/passwords"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrInvalidResetToken = errors.New("重置链接无效或已过期")
+
+// PasswordResetService 通过邮件中的一次性链接重置密码
+type PasswordResetService struct {
+	DB        *gorm.DB
+	Mailer    mail.Mailer
+	Tokens    *TokenService
+	Passwords *passwords.Policy
+	Validator *passwords.Validator
+	TTL       time.Duration
+	PublicURL string
+}
+
+// RequestReset 为邮箱对应的用户生成重置令牌并发送邮件
+// 邮箱未注册时同样返回成功，避免通过该接口探测已注册的邮箱
+func (s *PasswordResetService) RequestReset(email string) error {
+	var user models.UserModel
+	err := s.DB.Where("email = ?", email).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	rawToken, err := utils.RandomToken(32)
+	if err != nil {
+		return err
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// 只保留最新的一个链接，之前发出的链接全部作废
+		if err := tx.Unscoped().Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hashToken(rawToken),
+			ExpiresAt: time.Now().Add(s.TTL),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	link := strings.TrimRight(s.PublicURL, "/") + "/reset-password?token=" + url.QueryEscape(rawToken)
+	return s.Mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "重置密码",
+		Body: fmt.Sprintf("%s，你好：\n\n我们收到了重置你账号密码的请求，请在 %s 内打开下面的链接设置新密码：\n\n%s\n\n如果不是你本人操作，请忽略这封邮件。\n",
+			user.Username, s.TTL, link),
+	})
+}
+
+// ConfirmReset 校验重置令牌并设置新密码，成功后吊销该用户已签发的全部刷新令牌
+func (s *PasswordResetService) ConfirmReset(rawToken string, password string) error {
+	var token models.PasswordResetToken
+	err := s.DB.Where("token_hash = ?", hashToken(rawToken)).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+	var user models.UserModel
+	if err := s.DB.First(&user, token.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if err := s.Validator.Validate(password, user.Username, user.Email); err != nil {
+		return err
+	}
+	hashedPassword, err := s.Passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// 带条件更新，保证同一个令牌只能成功使用一次
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+		return tx.Model(&models.UserModel{}).Where("id = ?", token.UserID).Update("password", hashedPassword).Error
+	})
+	if err != nil {
+		return err
+	}
+	return s.Tokens.RevokeAllForUser(token.UserID)
+}
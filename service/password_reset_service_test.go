@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"goDemo/config"
+	"goDemo/mail"
+	"goDemo/models"
+	"goDemo/passwords"
+	"gorm.io/gorm"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var resetLinkPattern = regexp.MustCompile(`token=(\S+)`)
+
+func newPasswordResetService(t *testing.T, db *gorm.DB, mailer mail.Mailer) *PasswordResetService {
+	t.Helper()
+	cfg := config.Default().Auth
+	// 测试中用最低成本的 bcrypt，避免 argon2id 拖慢测试
+	cfg.PasswordHashing.Algorithm = "bcrypt"
+	cfg.PasswordHashing.BcryptCost = 4
+	policy, err := passwords.New(cfg.PasswordHashing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := passwords.NewValidator(cfg.PasswordPolicy, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &PasswordResetService{
+		DB:        db,
+		Mailer:    mailer,
+		Tokens:    &TokenService{DB: db},
+		Passwords: policy,
+		Validator: validator,
+		TTL:       time.Hour,
+		PublicURL: "http://realworld.test",
+	}
+}
+
+// resetToken 从发给 email 的最后一封邮件中取出重置令牌
+func resetToken(t *testing.T, mailer *mail.MemoryMailer, email string) string {
+	t.Helper()
+	msg, ok := mailer.Last(email)
+	if !ok {
+		t.Fatalf("没有发给 %s 的邮件", email)
+	}
+	match := resetLinkPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("邮件中没有重置链接：%q", msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func checkPassword(t *testing.T, s *PasswordResetService, db *gorm.DB, userID uint, password string) bool {
+	t.Helper()
+	var user models.UserModel
+	if err := db.First(&user, userID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Password == "" {
+		return false
+	}
+	ok, _, err := s.Passwords.Verify(password, user.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
+
+func TestPasswordResetTokenWorksOnce(t *testing.T) {
+	db := newTestDB(t)
+	mailer := &mail.MemoryMailer{}
+	s := newPasswordResetService(t, db, mailer)
+	alice := createUser(t, db, "alice")
+
+	if err := s.RequestReset(alice.Email); err != nil {
+		t.Fatal(err)
+	}
+	token := resetToken(t, mailer, alice.Email)
+	if err := s.ConfirmReset(token, "N3w-passphrase"); err != nil {
+		t.Fatalf("ConfirmReset() = %v, want nil", err)
+	}
+	if !checkPassword(t, s, db, alice.ID, "N3w-passphrase") {
+		t.Error("新密码没有生效")
+	}
+	if err := s.ConfirmReset(token, "An0ther-passphrase"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("second ConfirmReset() = %v, want ErrInvalidResetToken", err)
+	}
+	if !checkPassword(t, s, db, alice.ID, "N3w-passphrase") {
+		t.Error("已使用的令牌修改了密码")
+	}
+}
+
+func TestPasswordResetExpiredToken(t *testing.T) {
+	db := newTestDB(t)
+	mailer := &mail.MemoryMailer{}
+	s := newPasswordResetService(t, db, mailer)
+	alice := createUser(t, db, "alice")
+
+	if err := s.RequestReset(alice.Email); err != nil {
+		t.Fatal(err)
+	}
+	token := resetToken(t, mailer, alice.Email)
+	err := db.Model(&models.PasswordResetToken{}).Where("user_id = ?", alice.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ConfirmReset(token, "N3w-passphrase"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("ConfirmReset() with expired token = %v, want ErrInvalidResetToken", err)
+	}
+	if checkPassword(t, s, db, alice.ID, "N3w-passphrase") {
+		t.Error("过期的令牌修改了密码")
+	}
+}
+
+func TestPasswordResetNewRequestInvalidatesOldToken(t *testing.T) {
+	db := newTestDB(t)
+	mailer := &mail.MemoryMailer{}
+	s := newPasswordResetService(t, db, mailer)
+	alice := createUser(t, db, "alice")
+
+	if err := s.RequestReset(alice.Email); err != nil {
+		t.Fatal(err)
+	}
+	first := resetToken(t, mailer, alice.Email)
+	if err := s.RequestReset(alice.Email); err != nil {
+		t.Fatal(err)
+	}
+	second := resetToken(t, mailer, alice.Email)
+	if err := s.ConfirmReset(first, "N3w-passphrase"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("ConfirmReset() with superseded token = %v, want ErrInvalidResetToken", err)
+	}
+	if err := s.ConfirmReset(second, "N3w-passphrase"); err != nil {
+		t.Errorf("ConfirmReset() with latest token = %v, want nil", err)
+	}
+}
+
+func TestPasswordResetUnknownEmail(t *testing.T) {
+	db := newTestDB(t)
+	mailer := &mail.MemoryMailer{}
+	s := newPasswordResetService(t, db, mailer)
+
+	if err := s.RequestReset("nobody@example.com"); err != nil {
+		t.Errorf("RequestReset() for unknown email = %v, want nil", err)
+	}
+	if n := len(mailer.Messages()); n != 0 {
+		t.Errorf("sent %d messages for unknown email, want 0", n)
+	}
+	if err := s.ConfirmReset("not-a-token", "N3w-passphrase"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("ConfirmReset() with unknown token = %v, want ErrInvalidResetToken", err)
+	}
+}
@@ -2,14 +2,23 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"goDemo/lockout"
 	"goDemo/models"
+	"goDemo/policy"
 	"gorm.io/gorm"
 )
 
 type ProfileService struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Lockout *lockout.Limiter
 }
 
+var (
+	ErrInvalidRole   = fmt.Errorf("无效的角色，可选值：%v", policy.Roles())
+	ErrChangeOwnRole = errors.New("不能修改自己的角色")
+)
+
 // GetProfile 获取个人资料
 func (s *ProfileService) GetProfile(currentUserID uint, username string) (*models.Profile, error) {
 	var user models.UserModel
@@ -115,3 +124,77 @@ func (s *ProfileService) UnfollowUser(currentUserID uint, username string) (*mod
 		Following: false,
 	}, nil
 }
+
+// ListUsersWithRoles 列出用户及其角色，role 为空时返回全部用户
+func (s *ProfileService) ListUsersWithRoles(actor *models.UserModel, role string) ([]models.UserModel, error) {
+	if !policy.Can(actor, policy.ManageRoles) {
+		return nil, policy.ErrForbidden
+	}
+	query := s.DB.Model(&models.UserModel{}).Order("id")
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	var users []models.UserModel
+	err := query.Find(&users).Error
+	return users, err
+}
+
+// UpdateRole 修改指定用户的角色，需要角色管理权限
+// 管理员不能修改自己的角色，避免系统中失去最后一个管理员
+func (s *ProfileService) UpdateRole(actor *models.UserModel, username string, role string) (*models.UserModel, error) {
+	if !policy.Can(actor, policy.ManageRoles) {
+		return nil, policy.ErrForbidden
+	}
+	if actor.Username == username {
+		return nil, ErrChangeOwnRole
+	}
+	if !policy.ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	var user models.UserModel
+	err := s.DB.Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.Authorize(policy.CanUpdateRole(actor, &user)); err != nil {
+		return nil, err
+	}
+	err = s.DB.Model(&user).Update("role", role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UnlockUser 解除用户的登录锁定，需要用户管理权限
+func (s *ProfileService) UnlockUser(actor *models.UserModel, username string) (*models.UserModel, error) {
+	if !policy.Can(actor, policy.ManageUsers) {
+		return nil, policy.ErrForbidden
+	}
+	var user models.UserModel
+	err := s.DB.Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Lockout.Unlock(user.Email); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// AssignRole 直接修改用户角色，不做权限校验，供命令行初始化管理员使用
+func (s *ProfileService) AssignRole(username string, role string) (*models.UserModel, error) {
+	if !policy.ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	var user models.UserModel
+	err := s.DB.Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	err = s.DB.Model(&user).Update("role", role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
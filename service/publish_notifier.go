@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"goDemo/mail"
+	"goDemo/models"
+	"goDemo/webhook"
+	"gorm.io/gorm"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// PublishNotifier 实现 PublishListener：推送 article.published 事件，并按配置给作者的关注者发邮件。
+// 通知失败只记录日志，不影响发布本身
+type PublishNotifier struct {
+	DB     *gorm.DB
+	Mailer mail.Mailer
+	// Webhooks 为 nil 表示不推送
+	Webhooks        *webhook.Sender
+	NotifyFollowers bool
+	PublicURL       string
+}
+
+// ArticlePublished 实现 PublishListener
+func (n *PublishNotifier) ArticlePublished(article *models.Article) {
+	link := strings.TrimRight(n.PublicURL, "/") + "/article/" + url.PathEscape(article.Slug)
+	if n.Webhooks != nil {
+		if err := n.sendWebhook(article, link); err != nil {
+			log.Printf("推送文章 %s 的发布事件失败：%v", article.Slug, err)
+		}
+	}
+	if n.NotifyFollowers {
+		if err := n.mailFollowers(article, link); err != nil {
+			log.Printf("发送文章 %s 的发布通知邮件失败：%v", article.Slug, err)
+		}
+	}
+}
+
+func (n *PublishNotifier) sendWebhook(article *models.Article, link string) error {
+	payload := models.ArticlePublishedPayload{
+		Slug:        article.Slug,
+		Title:       article.Title,
+		Description: article.Description,
+		TagList:     article.TagList,
+		Author:      article.Author.Username,
+		URL:         link,
+	}
+	if article.PublishedAt != nil {
+		payload.PublishedAt = *article.PublishedAt
+	}
+	event, err := webhook.NewEvent(models.ArticlePublishedEvent, payload)
+	if err != nil {
+		return err
+	}
+	return n.Webhooks.Send(event)
+}
+
+// mailFollowers 只发给邮箱已验证的关注者，单封邮件失败不影响其他关注者
+func (n *PublishNotifier) mailFollowers(article *models.Article, link string) error {
+	var followers []models.UserModel
+	err := n.DB.Where("id IN (?) AND email_verified_at IS NOT NULL",
+		n.DB.Model(&models.Follow{}).Select("follower").Where("followed = ?", article.AuthorID)).
+		Find(&followers).Error
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, follower := range followers {
+		err := n.Mailer.Send(mail.Message{
+			To:      follower.Email,
+			Subject: fmt.Sprintf("%s 发布了新文章：%s", article.Author.Username, article.Title),
+			Body: fmt.Sprintf("%s，你好：\n\n你关注的 %s 发布了新文章《%s》：\n\n%s\n\n%s\n\n不想再收到通知可以取消关注该作者。\n",
+				follower.Username, article.Author.Username, article.Title, article.Description, link),
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s：%w", follower.Email, err))
+		}
+	}
+	return errors.Join(errs...)
+}
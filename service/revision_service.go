@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"goDemo/models"
+	"goDemo/policy"
+	"gorm.io/gorm"
+	"strings"
+)
+
+var ErrRevisionNotFound = errors.New("版本不存在")
+
+// RevisionService 文章的历史版本：列出、比较和恢复。版本在创建和修改文章时由 ArticleService 记录
+type RevisionService struct {
+	DB       *gorm.DB
+	Articles *ArticleService
+}
+
+// List 列出文章的所有版本，最新的在前
+func (s *RevisionService) List(actor *models.UserModel, slug string) ([]models.ArticleRevision, error) {
+	article, err := s.article(actor, slug)
+	if err != nil {
+		return nil, err
+	}
+	var revisions []models.ArticleRevision
+	err = withEditor(s.DB).Where("article_id = ?", article.ID).Order("number DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+// Get 获取文章的某个版本
+func (s *RevisionService) Get(actor *models.UserModel, slug string, number int) (*models.ArticleRevision, error) {
+	article, err := s.article(actor, slug)
+	if err != nil {
+		return nil, err
+	}
+	return s.revision(article.ID, number)
+}
+
+// Diff 比较两个版本，to 为 0 时表示最新版本，from 为 0 时表示 to 的上一个版本
+func (s *RevisionService) Diff(actor *models.UserModel, slug string, from int, to int) (*models.RevisionDiff, error) {
+	article, err := s.article(actor, slug)
+	if err != nil {
+		return nil, err
+	}
+	if to == 0 {
+		if err := s.DB.Model(&models.ArticleRevision{}).Where("article_id = ?", article.ID).
+			Select("COALESCE(MAX(number), 0)").Scan(&to).Error; err != nil {
+			return nil, err
+		}
+	}
+	if from == 0 {
+		from = to - 1
+	}
+	newer, err := s.revision(article.ID, to)
+	if err != nil {
+		return nil, err
+	}
+	// 与第一个版本之前比较时，旧版本视为空文章
+	older := &models.ArticleRevision{}
+	if from > 0 {
+		if older, err = s.revision(article.ID, from); err != nil {
+			return nil, err
+		}
+	}
+	return &models.RevisionDiff{
+		From:        from,
+		To:          to,
+		Title:       diffLines(splitLines(older.Title), splitLines(newer.Title)),
+		Description: diffLines(splitLines(older.Description), splitLines(newer.Description)),
+		Body:        diffLines(splitLines(older.Body), splitLines(newer.Body)),
+		TagList:     diffLines(older.TagList, newer.TagList),
+	}, nil
+}
+
+// Restore 用旧版本的内容覆盖文章，并记录为一个新版本，不改变文章状态；标题变化时与修改文章一样处理 slug
+func (s *RevisionService) Restore(actor *models.UserModel, slug string, number int) (*models.Article, error) {
+	article, err := s.article(actor, slug)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := s.revision(article.ID, number)
+	if err != nil {
+		return nil, err
+	}
+	// 较早的版本中的标签可能未经规范化
+	tags, err := NormalizeTags(revision.TagList)
+	if err != nil {
+		return nil, err
+	}
+	regenerate := !article.SlugPinned && article.Title != revision.Title
+	article.Title = revision.Title
+	article.Description = revision.Description
+	article.Body = revision.Body
+	article.TagList = tags
+	err = s.Articles.saveWithSlug(article, "", regenerate, func(tx *gorm.DB) error {
+		if err := tx.Save(article).Error; err != nil {
+			return err
+		}
+		if err := setArticleTags(tx, article.ID, article.TagList); err != nil {
+			return err
+		}
+		return recordRevision(tx, article, actor.ID, &number)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.DB.Preload("Author").First(article, article.ID).Error; err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// article 查找 actor 可以查看历史版本的文章，看不到文章时返回 ErrArticleNotFound
+func (s *RevisionService) article(actor *models.UserModel, slug string) (*models.Article, error) {
+	article, err := s.Articles.findArticle(actor, slug)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.Authorize(policy.CanViewRevisions(actor, article)); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+func (s *RevisionService) revision(articleID uint, number int) (*models.ArticleRevision, error) {
+	var revision models.ArticleRevision
+	err := withEditor(s.DB).Where("article_id = ? AND number = ?", articleID, number).First(&revision).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// recordRevision 以文章当前的内容追加一个版本，需要与保存文章在同一事务中调用；
+// 并发修改同一篇文章时版本号冲突，其中一个事务会失败回滚
+func recordRevision(tx *gorm.DB, article *models.Article, editorID uint, restoredFrom *int) error {
+	var last int
+	if err := tx.Model(&models.ArticleRevision{}).Where("article_id = ?", article.ID).
+		Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.ArticleRevision{
+		ArticleID:    article.ID,
+		Number:       last + 1,
+		EditorID:     editorID,
+		Title:        article.Title,
+		Description:  article.Description,
+		Body:         article.Body,
+		TagList:      article.TagList,
+		RestoredFrom: restoredFrom,
+	}).Error
+}
+
+// withEditor 预加载修改人，已注销的账号也要能显示
+func withEditor(db *gorm.DB) *gorm.DB {
+	return db.Preload("Editor", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+}
+
+// splitLines 按行拆分，统一换行符，空字符串没有任何行
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// diffLines 逐行比较，替换输出为先删除后插入
+func diffLines(a []string, b []string) []models.DiffLine {
+	lines := []models.DiffLine{}
+	for _, op := range difflib.NewMatcher(a, b).GetOpCodes() {
+		if op.Tag == 'e' {
+			for i := op.I1; i < op.I2; i++ {
+				lines = append(lines, models.DiffLine{Op: models.DiffEqual, Text: a[i], OldLine: i + 1, NewLine: op.J1 + i - op.I1 + 1})
+			}
+			continue
+		}
+		if op.Tag == 'r' || op.Tag == 'd' {
+			for i := op.I1; i < op.I2; i++ {
+				lines = append(lines, models.DiffLine{Op: models.DiffDelete, Text: a[i], OldLine: i + 1})
+			}
+		}
+		if op.Tag == 'r' || op.Tag == 'i' {
+			for j := op.J1; j < op.J2; j++ {
+				lines = append(lines, models.DiffLine{Op: models.DiffInsert, Text: b[j], NewLine: j + 1})
+			}
+		}
+	}
+	return lines
+}
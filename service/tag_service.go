@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"goDemo/models"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidTag = errors.New("标签不能超过 64 个字符")
+
+// maxTagLength 规范化后标签的最大长度（字符数）
+const maxTagLength = 64
+
+// TagService 标签统计
+type TagService struct {
+	DB *gorm.DB
+}
+
+// Popular 按使用标签的已发布文章数量从多到少列出标签，数量相同时按名称排序；没有已发布文章的标签不列出
+func (s *TagService) Popular(limit int) ([]models.TagCount, error) {
+	var counts []models.TagCount
+	err := s.DB.Model(&models.Tag{}).
+		Select("tags.name AS tag, COUNT(*) AS articles_count").
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Joins("JOIN articles ON articles.id = article_tags.article_id AND articles.deleted_at IS NULL").
+		Where("articles.status = ?", models.ArticlePublished).
+		Group("tags.id, tags.name").
+		Order("articles_count DESC, tags.name").
+		Limit(limit).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// NormalizeTag 规范化标签：Unicode NFKC 规范化（全角字符转为半角等），去掉首尾空白，
+// 中间连续的空白合并为一个空格，再转为小写
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(norm.NFKC.String(name)), " "))
+}
+
+// NormalizeTags 规范化标签列表，去掉空标签和重复的标签，保持原有顺序
+func NormalizeTags(names []string) (models.TagList, error) {
+	tags := models.TagList{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		tag := NormalizeTag(name)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, ErrInvalidTag
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// setArticleTags 用已规范化的标签替换文章的标签关联，不存在的标签一并创建
+func setArticleTags(tx *gorm.DB, articleID uint, names []string) error {
+	if err := tx.Where("article_id = ?", articleID).Delete(&models.ArticleTag{}).Error; err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i].Name = name
+	}
+	// 其他文章可能同时创建同名标签，已存在时忽略
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return err
+	}
+	var ids []uint
+	if err := tx.Model(&models.Tag{}).Where("name IN ?", names).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	links := make([]models.ArticleTag, len(ids))
+	for i, id := range ids {
+		links[i] = models.ArticleTag{ArticleID: articleID, TagID: id}
+	}
+	return tx.Create(&links).Error
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"goDemo/models"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("无效或已过期的刷新令牌")
+	// ErrRefreshTokenReused 已轮换的刷新令牌被再次使用，整个家族已被吊销
+	ErrRefreshTokenReused = errors.New("刷新令牌已被使用，请重新登录")
+	ErrSessionNotFound    = errors.New("会话不存在或已退出")
+)
+
+// sessionTouchInterval 会话最近活动时间的更新间隔，避免每个请求都写库
+const sessionTouchInterval = time.Minute
+
+// Client 发起登录或刷新的客户端，记录在会话中便于用户辨认设备
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+// TokenService 管理刷新令牌和访问令牌吊销
+type TokenService struct {
+	DB         *gorm.DB
+	Auth       *utils.Auth
+	RefreshTTL time.Duration
+}
+
+// IssueTokens 登录成功后创建会话并签发新的令牌家族
+func (s *TokenService) IssueTokens(user *models.UserModel, client Client) (accessToken string, refreshToken string, err error) {
+	familyID, err := utils.RandomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Create(&models.Session{
+			UserID:       user.ID,
+			FamilyID:     familyID,
+			UserAgent:    truncate(client.UserAgent, 255),
+			IP:           truncate(client.IP, 64),
+			LastActiveAt: now,
+			ExpiresAt:    now.Add(s.RefreshTTL),
+		}).Error
+		if err != nil {
+			return err
+		}
+		accessToken, refreshToken, err = s.issue(tx, user, familyID)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// Refresh 用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随即失效
+func (s *TokenService) Refresh(rawRefreshToken string, client Client) (*models.UserModel, string, string, error) {
+	var token models.RefreshToken
+	err := s.DB.Where("token_hash = ?", hashToken(rawRefreshToken)).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", "", ErrInvalidRefreshToken
+		}
+		return nil, "", "", err
+	}
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, "", "", ErrInvalidRefreshToken
+	}
+	if token.UsedAt != nil {
+		// 已轮换的令牌再次出现，说明令牌可能被盗，吊销整个家族
+		if err := s.RevokeFamily(token.FamilyID); err != nil {
+			return nil, "", "", err
+		}
+		return nil, "", "", ErrRefreshTokenReused
+	}
+
+	var user models.UserModel
+	if err := s.DB.First(&user, token.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", "", ErrInvalidRefreshToken
+		}
+		return nil, "", "", err
+	}
+
+	var accessToken, refreshToken string
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// 带条件更新，防止并发请求用同一个刷新令牌各换出一组新令牌
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+		now := time.Now()
+		err := tx.Model(&models.Session{}).
+			Where("family_id = ?", token.FamilyID).
+			Updates(map[string]interface{}{
+				"user_agent":     truncate(client.UserAgent, 255),
+				"ip":             truncate(client.IP, 64),
+				"last_active_at": now,
+				"expires_at":     now.Add(s.RefreshTTL),
+			}).Error
+		if err != nil {
+			return err
+		}
+		accessToken, refreshToken, err = s.issue(tx, &user, token.FamilyID)
+		return err
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		// 并发请求抢先轮换了同一个令牌，与重复使用同样处理，否则被盗的令牌可以借并发保住新换出的令牌
+		if err := s.RevokeFamily(token.FamilyID); err != nil {
+			return nil, "", "", err
+		}
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+	return &user, accessToken, refreshToken, nil
+}
+
+// Logout 吊销当前访问令牌以及它所属的刷新令牌家族
+func (s *TokenService) Logout(claims *utils.Claims) error {
+	if claims.FamilyID != "" {
+		if err := s.RevokeFamily(claims.FamilyID); err != nil {
+			return err
+		}
+	}
+	if claims.ID != "" {
+		return s.Revoke(claims.ID, claims.ExpiresAt)
+	}
+	return nil
+}
+
+// RevokeFamily 结束一个会话，吊销该家族下的所有刷新令牌
+func (s *TokenService) RevokeFamily(familyID string) error {
+	now := time.Now()
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Session{}).
+			Where("family_id = ? AND revoked_at IS NULL", familyID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", familyID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeAllForUser 结束用户的全部会话并吊销全部刷新令牌，例如修改密码后
+func (s *TokenService) RevokeAllForUser(userID uint) error {
+	now := time.Now()
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// ListSessions 列出用户仍然有效的会话，最近活动的在前
+func (s *TokenService) ListSessions(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := s.activeSessions(s.DB, time.Now()).
+		Where("user_id = ?", userID).
+		Order("last_active_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// RevokeSession 结束用户自己的某个会话，可以是当前会话
+func (s *TokenService) RevokeSession(userID uint, sessionID uint) error {
+	var session models.Session
+	err := s.activeSessions(s.DB, time.Now()).
+		Where("id = ? AND user_id = ?", sessionID, userID).
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	return s.RevokeFamily(session.FamilyID)
+}
+
+// RevokeOtherSessions 结束除 currentFamilyID 以外的全部会话
+func (s *TokenService) RevokeOtherSessions(userID uint, currentFamilyID string) error {
+	now := time.Now()
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Session{}).
+			Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, currentFamilyID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, currentFamilyID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// TouchSession 实现 utils.SessionStore：会话已退出或已过期时返回 false，否则记录最近活动时间
+func (s *TokenService) TouchSession(familyID string) (bool, error) {
+	now := time.Now()
+	var session models.Session
+	err := s.activeSessions(s.DB, now).Where("family_id = ?", familyID).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if now.Sub(session.LastActiveAt) >= sessionTouchInterval {
+		err := s.DB.Model(&models.Session{}).
+			Where("id = ? AND last_active_at < ?", session.ID, now.Add(-sessionTouchInterval)).
+			Update("last_active_at", now).Error
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (s *TokenService) activeSessions(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("revoked_at IS NULL AND expires_at > ?", now)
+}
+
+// IsRevoked 实现 utils.TokenDenylist
+func (s *TokenService) IsRevoked(jti string) (bool, error) {
+	var count int64
+	err := s.DB.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Revoke 实现 utils.TokenDenylist，顺带清理已经自然过期的记录
+func (s *TokenService) Revoke(jti string, expiresAt time.Time) error {
+	if err := s.DB.Unscoped().Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(s.Auth.TokenTTL)
+	}
+	var count int64
+	if err := s.DB.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return s.DB.Create(&models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+// issue 在指定家族下签发访问令牌和刷新令牌
+func (s *TokenService) issue(db *gorm.DB, user *models.UserModel, familyID string) (string, string, error) {
+	accessToken, err := s.Auth.GenerateToken(user, familyID)
+	if err != nil {
+		return "", "", err
+	}
+	rawRefreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	err = db.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(rawRefreshToken),
+		ExpiresAt: time.Now().Add(s.RefreshTTL),
+	}).Error
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, rawRefreshToken, nil
+}
+
+// truncate 按字符截断，避免超出列宽
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
+
+// hashToken 刷新令牌本身是高熵随机数，SHA-256 足以防止数据库泄露后被直接使用
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
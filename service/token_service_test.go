@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"goDemo/models"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func newTokenService(t *testing.T, db *gorm.DB) *TokenService {
+	t.Helper()
+	keys, err := utils.NewKeyring(0, utils.NewHMACKey("test", []byte("test-secret"), time.Time{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &TokenService{DB: db, Auth: utils.NewAuth(keys, time.Hour), RefreshTTL: time.Hour}
+}
+
+// assertFamilyRevoked 检查家族下的会话和全部刷新令牌都已吊销
+func assertFamilyRevoked(t *testing.T, db *gorm.DB, familyID string) {
+	t.Helper()
+	var tokens []models.RefreshToken
+	if err := db.Where("family_id = ?", familyID).Find(&tokens).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range tokens {
+		if token.RevokedAt == nil {
+			t.Errorf("refresh token %d 未被吊销", token.ID)
+		}
+	}
+	var active int64
+	db.Model(&models.Session{}).Where("family_id = ? AND revoked_at IS NULL", familyID).Count(&active)
+	if active != 0 {
+		t.Errorf("active sessions = %d, want 0", active)
+	}
+}
+
+func familyOf(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+	var session models.Session
+	if err := db.First(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+	return session.FamilyID
+}
+
+func TestRefreshRotates(t *testing.T) {
+	db := newTestDB(t)
+	s := newTokenService(t, db)
+	user := createUser(t, db, "alice")
+
+	_, refreshToken, err := s.IssueTokens(user, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, rotated, err := s.Refresh(refreshToken, Client{})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if rotated == refreshToken {
+		t.Error("Refresh() 应返回新的刷新令牌")
+	}
+	if _, _, _, err := s.Refresh(rotated, Client{}); err != nil {
+		t.Errorf("Refresh(rotated) error = %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	db := newTestDB(t)
+	s := newTokenService(t, db)
+	user := createUser(t, db, "alice")
+
+	_, refreshToken, err := s.IssueTokens(user, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, rotated, err := s.Refresh(refreshToken, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := s.Refresh(refreshToken, Client{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh(reused) error = %v, want ErrRefreshTokenReused", err)
+	}
+	assertFamilyRevoked(t, db, familyOf(t, db))
+	if _, _, _, err := s.Refresh(rotated, Client{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(rotated) error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRefreshConcurrentReuseRevokesFamily(t *testing.T) {
+	db := newTestDB(t)
+	s := newTokenService(t, db)
+	user := createUser(t, db, "alice")
+
+	_, refreshToken, err := s.IssueTokens(user, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	familyID := familyOf(t, db)
+
+	// 在条件更新之前模拟另一个请求抢先轮换了同一个令牌
+	raced := false
+	err = db.Callback().Update().Before("gorm:update").Register("test:race", func(tx *gorm.DB) {
+		if raced || tx.Statement.Table != "refresh_tokens" {
+			return
+		}
+		raced = true
+		_, err := tx.Statement.ConnPool.ExecContext(tx.Statement.Context,
+			"UPDATE refresh_tokens SET used_at = ? WHERE token_hash = ?", time.Now(), hashToken(refreshToken))
+		if err != nil {
+			tx.AddError(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := s.Refresh(refreshToken, Client{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh() error = %v, want ErrRefreshTokenReused", err)
+	}
+	if !raced {
+		t.Fatal("没有触发并发轮换")
+	}
+	// 抢先换出的令牌属于同一家族，吊销家族后同样失效
+	assertFamilyRevoked(t, db, familyID)
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+	"goDemo/models"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("已开启两步验证")
+	ErrTwoFactorNotEnrolled    = errors.New("请先绑定认证器")
+	ErrTwoFactorNotEnabled     = errors.New("未开启两步验证")
+	ErrInvalidTwoFactorCode    = errors.New("验证码错误")
+	ErrInvalidChallengeToken   = errors.New("登录已超时，请重新输入密码")
+)
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// TwoFactorService 基于 TOTP（RFC 6238）的两步验证
+type TwoFactorService struct {
+	DB           *gorm.DB
+	Auth         *utils.Auth
+	Issuer       string
+	ChallengeTTL time.Duration
+}
+
+// Enroll 生成新的 TOTP 密钥，确认前不会生效，重复调用会替换未确认的密钥
+func (s *TwoFactorService) Enroll(user *models.UserModel) (*models.TwoFactorEnrollResponse, error) {
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	secret, err := utils.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.DB.Model(user).Update("totp_secret", secret).Error; err != nil {
+		return nil, err
+	}
+	return &models.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: utils.TOTPURI(s.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm 用认证器上的验证码确认绑定，成功后开启两步验证并返回恢复码
+func (s *TwoFactorService) Confirm(user *models.UserModel, code string) ([]string, error) {
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	counter, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	var codes []string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(user).Updates(map[string]interface{}{
+			"totp_enabled_at":   time.Now(),
+			"totp_last_counter": counter,
+		}).Error
+		if err != nil {
+			return err
+		}
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable 关闭两步验证，需要提供验证码或恢复码
+func (s *TwoFactorService) Disable(user *models.UserModel, code string) error {
+	if !user.TwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := s.verifyCode(user, code); err != nil {
+		return err
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(user).Updates(map[string]interface{}{
+			"totp_secret":       "",
+			"totp_enabled_at":   nil,
+			"totp_last_counter": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧的恢复码全部作废，需要提供验证码
+func (s *TwoFactorService) RegenerateRecoveryCodes(user *models.UserModel, code string) ([]string, error) {
+	if !user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err := s.verifyCode(user, code); err != nil {
+		return nil, err
+	}
+	var codes []string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	return codes, err
+}
+
+// Challenge 密码验证通过后签发挑战令牌，客户端凭它和验证码完成登录
+func (s *TwoFactorService) Challenge(user *models.UserModel) (string, error) {
+	return s.Auth.GenerateChallengeToken(user, s.ChallengeTTL)
+}
+
+// ParseChallenge 校验挑战令牌，返回待完成登录的用户
+func (s *TwoFactorService) ParseChallenge(challengeToken string) (*models.UserModel, error) {
+	userID, err := s.Auth.ParseChallengeToken(challengeToken)
+	if err != nil {
+		return nil, ErrInvalidChallengeToken
+	}
+	var user models.UserModel
+	err = s.DB.First(&user, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidChallengeToken
+		}
+		return nil, err
+	}
+	if !user.TwoFactorEnabled() {
+		return nil, ErrInvalidChallengeToken
+	}
+	return &user, nil
+}
+
+// CompleteLogin 校验挑战令牌对应用户的验证码，成功后由调用方签发访问令牌
+func (s *TwoFactorService) CompleteLogin(user *models.UserModel, code string) error {
+	return s.verifyCode(user, code)
+}
+
+// verifyCode 依次尝试 TOTP 验证码和恢复码，验证码和恢复码都只能使用一次
+func (s *TwoFactorService) verifyCode(user *models.UserModel, code string) error {
+	if counter, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		// 带条件更新，同一个时间窗口的验证码只能成功使用一次
+		result := s.DB.Model(&models.UserModel{}).
+			Where("id = ? AND totp_last_counter < ?", user.ID, counter).
+			Update("totp_last_counter", counter)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+	result := s.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 删除旧的恢复码并生成新的一组
+func (s *TwoFactorService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.RandomToken(8)
+		if err != nil {
+			return nil, err
+		}
+		// 每 4 位一组，方便抄写
+		codes = append(codes, raw[0:4]+"-"+raw[4:8]+"-"+raw[8:12]+"-"+raw[12:16])
+		records = append(records, models.RecoveryCode{UserID: userID, CodeHash: hashToken(raw)})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode 忽略大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
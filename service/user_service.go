@@ -2,105 +2,131 @@ package service
 
 import (
 	"errors"
-	"fmt"
-	"github.com/gin-gonic/gin"
 	"goDemo/models"
+	"goDemo/passwords"
+	"goDemo/policy"
 	"goDemo/utils"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
-	"strings"
 )
 
 type UserService struct {
-	DB   *gorm.DB
-	Auth *utils.Auth
+	DB        *gorm.DB
+	Auth      *utils.Auth
+	Passwords *passwords.Policy
+	// PasswordValidator 新密码需要满足的策略
+	PasswordValidator *passwords.Validator
+	// Invitations 注册方式与邀请码
+	Invitations *InvitationService
 }
 
-// CreateUser 注册用户
-func (s *UserService) CreateUser(user *models.UserModel) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+// CreateUser 注册用户，invitationCode 不为空时使用该邀请码并记录邀请人
+func (s *UserService) CreateUser(user *models.UserModel, invitationCode string) error {
+	if err := s.Invitations.CheckRegistration(invitationCode); err != nil {
+		return err
+	}
+	if err := s.PasswordValidator.Validate(user.Password, user.Username, user.Email); err != nil {
+		return err
+	}
+	hashedPassword, err := s.Passwords.Hash(user.Password)
 	if err != nil {
 		return err
 	}
-	user.Password = string(hashedPassword)
-	fmt.Printf("存储的哈希密码: %s\n", user.Password)
-	return s.DB.Create(user).Error
+	user.Password = hashedPassword
+	user.Role = policy.RoleUser
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if invitationCode != "" {
+			if err := s.Invitations.Redeem(tx, invitationCode, user); err != nil {
+				return err
+			}
+		}
+		return tx.Create(user).Error
+	})
 }
 
-// VerifyUser 验证用户信息
+// VerifyUser 验证用户信息，邮箱不存在或密码错误时返回 nil, nil
+// 密码正确但哈希的算法或参数已过时，会按当前配置重新哈希
 func (s *UserService) VerifyUser(email string, password string) (*models.UserModel, error) {
 	var user models.UserModel
 	err := s.DB.Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 同样计算一次哈希，避免通过响应时间判断邮箱是否已注册
+			_, _ = s.Passwords.Hash(password)
 			return nil, nil // 用户不存在
 		}
 		return nil, err
 	}
-	// 去除哈希密码两端的空白字符
-	trimmedPassword := strings.TrimSpace(user.Password)
-	fmt.Printf("从数据库读取的哈希密码（去除空白后）: %s\n", trimmedPassword)
-	fmt.Printf("待验证的明文密码: %s\n", password)
-
-	// 使用 bcrypt 验证密码，目前自己电脑上实在不清楚为什么会验证失败，即使输入正确密码也会返回错误
-	// 所以暂时注释掉，直接返回用户信息，后续再解决
-	//err = bcrypt.CompareHashAndPassword([]byte(trimmedPassword), []byte(password))
-	//if err != nil {
-	//	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-	//		return nil, nil // 密码错误
-	//	}
-	//	return nil, err // 其他 bcrypt 错误
-	//}
-	return &user, nil
-}
-
-// GetUserByToken 根据token获取用户信息
-func (s *UserService) GetUserByToken(ctx *gin.Context) (*models.UserModel, error) {
-	userID, err := s.Auth.ParseToken(ctx)
+	if !user.HasPassword() {
+		_, _ = s.Passwords.Hash(password)
+		return nil, nil
+	}
+	ok, needsRehash, err := s.Passwords.Verify(password, user.Password)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, nil // 密码错误
+	}
+	if needsRehash {
+		hashedPassword, err := s.Passwords.Hash(password)
+		if err != nil {
+			return nil, err
+		}
+		// 带上旧哈希作为条件，避免覆盖同时发生的密码修改
+		err = s.DB.Model(&models.UserModel{}).
+			Where("id = ? AND password = ?", user.ID, user.Password).
+			Update("password", hashedPassword).Error
+		if err != nil {
+			return nil, err
+		}
+		user.Password = hashedPassword
+	}
+	return &user, nil
+}
 
-	// 根据用户 ID 从数据库中查询用户信息
+// GetUserByID 根据用户 ID 获取用户信息，用户不存在时返回 nil, nil
+func (s *UserService) GetUserByID(userID uint) (*models.UserModel, error) {
 	var user models.UserModel
-	err = s.DB.First(&user, userID).Error
+	err := s.DB.First(&user, userID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, nil
 		}
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (s *UserService) UpdateUser(ctx *gin.Context, updateRequest *models.UserUpdateRequest) (*models.UserModel, error) {
-	// 解析JWT令牌
-	userID, err := s.Auth.ParseToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-	// 根据用户ID查询用户
-	var user models.UserModel
-	err = s.DB.First(&user, userID).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+// UpdateUser 更新用户信息
+func (s *UserService) UpdateUser(user *models.UserModel, updateRequest *models.UserUpdateRequest) (*models.UserModel, error) {
+	// 新密码不能包含修改后的用户名和邮箱
+	if updateRequest.User.Password != nil {
+		username, email := user.Username, user.Email
+		if updateRequest.User.Username != nil {
+			username = *updateRequest.User.Username
+		}
+		if updateRequest.User.Email != nil {
+			email = *updateRequest.User.Email
+		}
+		if err := s.PasswordValidator.Validate(*updateRequest.User.Password, username, email); err != nil {
+			return nil, err
 		}
-		return nil, err
 	}
 	// 更新用户信息
-	if updateRequest.User.Email != nil {
+	if updateRequest.User.Email != nil && *updateRequest.User.Email != user.Email {
+		// 更换邮箱后需要重新验证
 		user.Email = *updateRequest.User.Email
+		user.EmailVerifiedAt = nil
 	}
 	if updateRequest.User.Username != nil {
 		user.Username = *updateRequest.User.Username
 	}
 	if updateRequest.User.Password != nil {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*updateRequest.User.Password), bcrypt.DefaultCost)
+		hashedPassword, err := s.Passwords.Hash(*updateRequest.User.Password)
 		if err != nil {
 			return nil, err
 		}
-		user.Password = string(hashedPassword)
+		user.Password = hashedPassword
 	}
 	if updateRequest.User.Bio != nil {
 		user.Bio = *updateRequest.User.Bio
@@ -108,9 +134,9 @@ func (s *UserService) UpdateUser(ctx *gin.Context, updateRequest *models.UserUpd
 	if updateRequest.User.Image != nil {
 		user.Image = *updateRequest.User.Image
 	}
-	err = s.DB.Save(&user).Error
+	err := s.DB.Save(user).Error
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return user, nil
 }
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"goDemo/mail"
+	"goDemo/models"
+	"goDemo/utils"
+	"gorm.io/gorm"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("验证链接无效或已过期")
+	ErrEmailAlreadyVerified     = errors.New("邮箱已验证")
+)
+
+// ResendTooSoonError 距离上次发送验证邮件的时间太短
+type ResendTooSoonError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ResendTooSoonError) Error() string {
+	return fmt.Sprintf("验证邮件发送过于频繁，请 %d 秒后再试", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// VerificationService 注册后通过邮件中的签名链接验证邮箱
+type VerificationService struct {
+	DB             *gorm.DB
+	Auth           *utils.Auth
+	Mailer         mail.Mailer
+	TTL            time.Duration
+	ResendInterval time.Duration
+	PublicURL      string
+}
+
+// Send 向用户当前的邮箱发送验证链接，并记录发送时间
+func (s *VerificationService) Send(user *models.UserModel) error {
+	now := time.Now()
+	if err := s.DB.Model(user).Update("verification_sent_at", now).Error; err != nil {
+		return err
+	}
+	return s.send(user)
+}
+
+// Resend 重新发送验证邮件，两次发送之间至少间隔 ResendInterval
+func (s *VerificationService) Resend(user *models.UserModel) error {
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	now := time.Now()
+	// 带条件更新，并发请求中只有一个能通过频率限制
+	result := s.DB.Model(&models.UserModel{}).
+		Where("id = ? AND (verification_sent_at IS NULL OR verification_sent_at <= ?)", user.ID, now.Add(-s.ResendInterval)).
+		Update("verification_sent_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		retryAfter := s.ResendInterval
+		if user.VerificationSentAt != nil {
+			retryAfter = user.VerificationSentAt.Add(s.ResendInterval).Sub(now)
+		}
+		return &ResendTooSoonError{RetryAfter: max(retryAfter, time.Second)}
+	}
+	return s.send(user)
+}
+
+// Verify 校验验证链接中的令牌，令牌签发后邮箱被修改过则视为无效
+func (s *VerificationService) Verify(rawToken string) (*models.UserModel, error) {
+	userID, email, err := s.Auth.ParseEmailToken(rawToken)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	var user models.UserModel
+	err = s.DB.First(&user, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+	if user.Email != email {
+		return nil, ErrInvalidVerificationToken
+	}
+	if user.EmailVerified() {
+		return &user, nil
+	}
+	now := time.Now()
+	if err := s.DB.Model(&user).Update("email_verified_at", now).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *VerificationService) send(user *models.UserModel) error {
+	token, err := s.Auth.GenerateEmailToken(user, s.TTL)
+	if err != nil {
+		return err
+	}
+	link := strings.TrimRight(s.PublicURL, "/") + "/verify-email?token=" + url.QueryEscape(token)
+	return s.Mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "验证邮箱",
+		Body: fmt.Sprintf("%s，你好：\n\n请在 %s 内打开下面的链接完成邮箱验证：\n\n%s\n\n如果你没有注册过账号，请忽略这封邮件。\n",
+			user.Username, s.TTL, link),
+	})
+}
@@ -0,0 +1,259 @@
+// Package idp 模拟的 OpenID Connect 身份提供方，供 go run ./test/mockoidc 和第三方登录的测试共用
+//
+// /authorize 不显示登录页，直接按查询参数中的 sub、email、email_verified、name 签发授权码并跳回 redirect_uri，
+// 便于脚本驱动整个流程；/token 会校验授权码、redirect_uri 和 PKCE。
+package idp
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// authorization 已签发、尚未兑换的授权码
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	claims        jwt.MapClaims
+	expiresAt     time.Time
+}
+
+// Server 模拟的身份提供方，Issuer 必须与应用配置的 issuer 一致
+type Server struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+
+	key   *rsa.PrivateKey
+	keyID string
+	mux   *http.ServeMux
+
+	mu    sync.Mutex
+	codes map[string]*authorization
+}
+
+// New 创建身份提供方并生成签名密钥，clientSecret 为空表示公开客户端
+func New(issuer string, clientID string, clientSecret string) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("生成签名密钥失败：%w", err)
+	}
+	s := &Server{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		keyID:        randomString(8),
+		mux:          http.NewServeMux(),
+		codes:        make(map[string]*authorization),
+	}
+	s.mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	s.mux.HandleFunc("/jwks", s.jwks)
+	s.mux.HandleFunc("/authorize", s.authorize)
+	s.mux.HandleFunc("/token", s.token)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Login 模拟浏览器打开授权地址：params 覆盖授权地址上的查询参数（如 sub、email、nonce），
+// 返回跳回 redirect_uri 时携带的 code 和 state
+func (s *Server) Login(authURL string, params url.Values) (code string, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	query := u.Query()
+	for k, v := range params {
+		query[k] = v
+	}
+	u.RawQuery = query.Encode()
+	recorder := httptest.NewRecorder()
+	s.authorize(recorder, httptest.NewRequest(http.MethodGet, u.String(), nil))
+	if recorder.Code != http.StatusFound {
+		return "", "", fmt.Errorf("授权失败：%d %s", recorder.Code, recorder.Body.String())
+	}
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.Issuer,
+		"authorization_endpoint":                s.Issuer + "/authorize",
+		"token_endpoint":                        s.Issuer + "/token",
+		"jwks_uri":                              s.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	encode := base64.RawURLEncoding.EncodeToString
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": s.keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   encode(s.key.N.Bytes()),
+			"e":   encode(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || q.Get("redirect_uri") == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("client_id") != s.ClientID || q.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id or response_type", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+	sub := valueOr(q.Get("sub"), "mock-user-1")
+	claims := jwt.MapClaims{
+		"sub":            sub,
+		"email":          valueOr(q.Get("email"), sub+"@mock.local"),
+		"email_verified": q.Get("email_verified") != "false",
+		"name":           valueOr(q.Get("name"), "Mock User"),
+	}
+	if username := q.Get("preferred_username"); username != "" {
+		claims["preferred_username"] = username
+	}
+	code := randomString(16)
+	s.mu.Lock()
+	s.codes[code] = &authorization{
+		clientID:      s.ClientID,
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+		claims:        claims,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	s.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", err.Error())
+		return
+	}
+	if s.ClientSecret != "" {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != s.ClientID || secret != s.ClientSecret {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type", "")
+		return
+	}
+	// 授权码只能兑换一次
+	s.mu.Lock()
+	auth, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+	if !ok || time.Now().After(auth.expiresAt) {
+		tokenError(w, "invalid_grant", "unknown or expired code")
+		return
+	}
+	if r.PostForm.Get("redirect_uri") != auth.redirectURI || r.PostForm.Get("client_id") != auth.clientID {
+		tokenError(w, "invalid_grant", "redirect_uri or client_id mismatch")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		tokenError(w, "invalid_grant", "PKCE verification failed")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": s.Issuer,
+		"aud": auth.clientID,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+	for k, v := range auth.claims {
+		claims[k] = v
+	}
+	if auth.nonce != "" {
+		claims["nonce"] = auth.nonce
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = s.keyID
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(16),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func valueOr(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
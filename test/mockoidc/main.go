@@ -0,0 +1,30 @@
+// mockoidc 本地模拟的 OpenID Connect 身份提供方，用于在没有真实提供方时联调第三方登录
+//
+// 启动：go run ./test/mockoidc -addr :9000 -client-id realworld
+// 配置：auth.oidc.providers 中添加 {name: mock, issuer: http://localhost:9000, client_id: realworld}
+//
+// /authorize 不显示登录页，直接按查询参数中的 sub、email、email_verified、name 签发授权码并跳回 redirect_uri，
+// 便于脚本驱动整个流程；/token 会校验授权码、redirect_uri 和 PKCE。
+package main
+
+import (
+	"flag"
+	"goDemo/test/mockoidc/idp"
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "监听地址")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer，必须与应用配置一致")
+	clientID := flag.String("client-id", "realworld", "允许的 client_id")
+	clientSecret := flag.String("client-secret", "", "client_secret，为空表示公开客户端")
+	flag.Parse()
+
+	s, err := idp.New(*issuer, *clientID, *clientSecret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("模拟身份提供方已启动：%s", s.Issuer)
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
@@ -1,66 +1,329 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"goDemo/models"
+	"goDemo/policy"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// TokenDenylist 已吊销的访问令牌，ParseToken 会逐个检查
+type TokenDenylist interface {
+	IsRevoked(jti string) (bool, error)
+	Revoke(jti string, expiresAt time.Time) error
+}
+
+// SessionStore 登录会话，ParseClaims 据此拒绝已退出或已过期会话签发的访问令牌
+type SessionStore interface {
+	TouchSession(familyID string) (bool, error)
+}
+
+// APIKeyStore 查找 API 密钥，密钥不存在、已过期或已吊销时返回 nil, nil
+type APIKeyStore interface {
+	ResolveAPIKey(rawKey string) (*models.APIKey, error)
+}
+
 type Auth struct {
-	SecretKey string
+	Keys     *Keyring
+	TokenTTL time.Duration
+	Denylist TokenDenylist
+	Sessions SessionStore
+	APIKeys  APIKeyStore
+}
+
+// Claims 访问令牌中携带的信息
+type Claims struct {
+	UserID    uint
+	ID        string // jti
+	FamilyID  string // 所属刷新令牌家族
+	ExpiresAt time.Time
+	// 通过 API 密钥认证时，APIKeyID 不为 0，Scopes 为密钥的授权范围
+	APIKeyID uint
+	Scopes   []policy.Scope
+	// 管理员代入时，UserID 为被代入的用户，ActorID 为发起代入的管理员
+	ActorID         uint
+	ImpersonationID uint
+}
+
+// ViaAPIKey 是否通过 API 密钥认证
+func (c *Claims) ViaAPIKey() bool {
+	return c.APIKeyID != 0
+}
+
+// Impersonated 是否为管理员代入签发的令牌
+func (c *Claims) Impersonated() bool {
+	return c.ActorID != 0
 }
 
-func NewAuth(secretKey string) *Auth {
+func NewAuth(keys *Keyring, tokenTTL time.Duration) *Auth {
 	return &Auth{
-		SecretKey: secretKey,
+		Keys:     keys,
+		TokenTTL: tokenTTL,
 	}
 }
 
 // GenerateToken 生成JWT token
-func (s *Auth) GenerateToken(user *models.UserModel) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+// familyID 为签发该令牌的刷新令牌家族，退出登录时据此吊销
+func (s *Auth) GenerateToken(user *models.UserModel, familyID string) (string, error) {
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	key, err := s.Keys.SigningKey(now)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, jwt.MapClaims{
 		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
+		"jti":     jti,
+		"fam":     familyID,
+		"iat":     now.Unix(),
+		"exp":     now.Add(s.TokenTTL).Unix(),
 	})
-	return token.SignedString([]byte(s.SecretKey))
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
 }
 
-// ParseToken 获取并解析JWT token，获取其中UserID
-func (s *Auth) ParseToken(ctx *gin.Context) (uint, error) {
+// GenerateImpersonationToken 生成代入令牌，act 声明（RFC 8693）记录发起代入的管理员
+// 代入令牌不属于任何登录会话，也没有刷新令牌，提前结束时按 jti 吊销
+func (s *Auth) GenerateImpersonationToken(subject *models.UserModel, actor *models.UserModel, impersonationID uint, jti string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	key, err := s.Keys.SigningKey(now)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, jwt.MapClaims{
+		"user_id": subject.ID,
+		"act":     map[string]string{"sub": strconv.FormatUint(uint64(actor.ID), 10)},
+		"imp":     impersonationID,
+		"jti":     jti,
+		"iat":     now.Unix(),
+		"exp":     expiresAt.Unix(),
+	})
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
+}
+
+// BearerToken 从 Authorization 请求头中取出 token 原文
+func BearerToken(ctx *gin.Context) (string, error) {
+	return authorizationCredentials(ctx, "bearer")
+}
+
+// APIKeyToken 从 "Authorization: Token <key>" 请求头中取出 API 密钥原文
+func APIKeyToken(ctx *gin.Context) (string, error) {
+	return authorizationCredentials(ctx, "token")
+}
+
+// authorizationCredentials 取出指定认证方案的凭证，scheme 不区分大小写
+func authorizationCredentials(ctx *gin.Context, scheme string) (string, error) {
 	authHeader := ctx.GetHeader("Authorization")
 	if authHeader == "" {
-		return 0, errors.New("missing Authorization header")
+		return "", errors.New("missing Authorization header")
 	}
 
 	splitToken := strings.Split(authHeader, " ")
-	if len(splitToken) != 2 || strings.ToLower(splitToken[0]) != "bearer" {
-		return 0, errors.New("invalid Authorization header format")
+	if len(splitToken) != 2 || strings.ToLower(splitToken[0]) != scheme {
+		return "", errors.New("invalid Authorization header format")
 	}
+	return splitToken[1], nil
+}
 
-	tokenString := splitToken[1]
-	parsedToken, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.SecretKey), nil
-	})
+// ParseToken 获取并解析JWT token，获取其中UserID
+func (s *Auth) ParseToken(ctx *gin.Context) (uint, error) {
+	claims, err := s.ParseClaims(ctx)
 	if err != nil {
 		return 0, err
 	}
+	return claims.UserID, nil
+}
 
-	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+// ParseClaims 获取并解析JWT token，返回完整的声明，已吊销的令牌视为无效
+// 使用 "Authorization: Token <key>" 时按 API 密钥认证
+func (s *Auth) ParseClaims(ctx *gin.Context) (*Claims, error) {
+	if rawKey, err := APIKeyToken(ctx); err == nil {
+		return s.parseAPIKey(rawKey)
+	}
+	tokenString, err := BearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedToken, err := jwt.Parse(tokenString, s.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	mapClaims, ok := parsedToken.Claims.(jwt.MapClaims)
 	if !ok || !parsedToken.Valid {
-		return 0, errors.New("invalid token")
+		return nil, errors.New("invalid token")
+	}
+	// 邮箱验证等一次性用途的令牌不能当作访问令牌使用
+	if _, ok := mapClaims["purpose"]; ok {
+		return nil, errors.New("invalid token")
 	}
 
-	userID, ok := claims["user_id"].(float64)
+	userID, ok := mapClaims["user_id"].(float64)
 	if !ok {
-		return 0, errors.New("invalid user ID in token")
+		return nil, errors.New("invalid user ID in token")
+	}
+	claims := &Claims{UserID: uint(userID)}
+	claims.ID, _ = mapClaims["jti"].(string)
+	claims.FamilyID, _ = mapClaims["fam"].(string)
+	if exp, err := mapClaims.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+	if act, ok := mapClaims["act"].(map[string]interface{}); ok {
+		actorSub, _ := act["sub"].(string)
+		actorID, err := strconv.ParseUint(actorSub, 10, 64)
+		if err != nil || actorID == 0 || claims.ID == "" {
+			return nil, errors.New("invalid impersonation token")
+		}
+		claims.ActorID = uint(actorID)
+		impersonationID, _ := mapClaims["imp"].(float64)
+		claims.ImpersonationID = uint(impersonationID)
 	}
 
-	return uint(userID), nil
+	if s.Denylist != nil && claims.ID != "" {
+		revoked, err := s.Denylist.IsRevoked(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, errors.New("token has been revoked")
+		}
+	}
+	if s.Sessions != nil && claims.FamilyID != "" {
+		active, err := s.Sessions.TouchSession(claims.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		if !active {
+			return nil, errors.New("session has been signed out")
+		}
+	}
+	return claims, nil
+}
+
+// parseAPIKey 校验 API 密钥并转换为声明
+func (s *Auth) parseAPIKey(rawKey string) (*Claims, error) {
+	if s.APIKeys == nil {
+		return nil, errors.New("API keys are not enabled")
+	}
+	key, err := s.APIKeys.ResolveAPIKey(rawKey)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("invalid API key")
+	}
+	claims := &Claims{UserID: key.UserID, APIKeyID: key.ID}
+	for _, scope := range key.ScopeList() {
+		claims.Scopes = append(claims.Scopes, policy.Scope(scope))
+	}
+	if key.ExpiresAt != nil {
+		claims.ExpiresAt = *key.ExpiresAt
+	}
+	return claims, nil
+}
+
+// keyFunc 根据 kid 请求头查找验证密钥
+func (s *Auth) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := s.Keys.VerificationKey(kid, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	// 算法必须与密钥登记的一致，防止算法混淆攻击
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// 一次性用途令牌的 purpose 声明，这类令牌不能当作访问令牌使用
+const (
+	purposeVerifyEmail        = "verify_email"
+	purposeTwoFactorChallenge = "2fa_challenge"
+)
+
+// GenerateEmailToken 生成邮箱验证令牌，令牌绑定用户和当时的邮箱，修改邮箱后旧链接自动失效
+func (s *Auth) GenerateEmailToken(user *models.UserModel, ttl time.Duration) (string, error) {
+	return s.signPurposeToken(purposeVerifyEmail, user.ID, jwt.MapClaims{"email": user.Email}, ttl)
+}
+
+// ParseEmailToken 校验邮箱验证令牌，返回用户 ID 和签发时的邮箱
+func (s *Auth) ParseEmailToken(tokenString string) (uint, string, error) {
+	userID, claims, err := s.parsePurposeToken(tokenString, purposeVerifyEmail)
+	if err != nil {
+		return 0, "", err
+	}
+	email, _ := claims["email"].(string)
+	return userID, email, nil
+}
+
+// GenerateChallengeToken 密码验证通过但还需要二次验证时签发的挑战令牌
+func (s *Auth) GenerateChallengeToken(user *models.UserModel, ttl time.Duration) (string, error) {
+	return s.signPurposeToken(purposeTwoFactorChallenge, user.ID, nil, ttl)
+}
+
+// ParseChallengeToken 校验挑战令牌，返回用户 ID
+func (s *Auth) ParseChallengeToken(tokenString string) (uint, error) {
+	userID, _, err := s.parsePurposeToken(tokenString, purposeTwoFactorChallenge)
+	return userID, err
+}
+
+// signPurposeToken 签发带 purpose 声明的短期令牌，extra 为附加声明
+func (s *Auth) signPurposeToken(purpose string, userID uint, extra jwt.MapClaims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	key, err := s.Keys.SigningKey(now)
+	if err != nil {
+		return "", err
+	}
+	claims := jwt.MapClaims{
+		"sub":     strconv.FormatUint(uint64(userID), 10),
+		"purpose": purpose,
+		"iat":     now.Unix(),
+		"exp":     now.Add(ttl).Unix(),
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
+}
+
+// parsePurposeToken 校验令牌签名、有效期和 purpose 声明
+func (s *Auth) parsePurposeToken(tokenString string, purpose string) (uint, jwt.MapClaims, error) {
+	parsedToken, err := jwt.Parse(tokenString, s.keyFunc)
+	if err != nil {
+		return 0, nil, err
+	}
+	mapClaims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok || !parsedToken.Valid || mapClaims["purpose"] != purpose {
+		return 0, nil, errors.New("invalid token")
+	}
+	sub, _ := mapClaims.GetSubject()
+	userID, err := strconv.ParseUint(sub, 10, 64)
+	if err != nil {
+		return 0, nil, errors.New("invalid user ID in token")
+	}
+	return uint(userID), mapClaims, nil
+}
+
+// RandomToken 生成 n 字节的随机数并以十六进制返回
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
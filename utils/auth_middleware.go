@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"github.com/gin-gonic/gin"
+	"goDemo/models"
+	"goDemo/policy"
+	"log"
+	"net/http"
+)
+
+// principalKey 认证主体在 gin.Context 中的键
+const principalKey = "auth.principal"
+
+// Principal 当前请求的认证主体
+type Principal struct {
+	User   *models.UserModel
+	Claims *Claims
+	// Actor 管理员代入时为发起代入的管理员，否则为 nil
+	Actor *models.UserModel
+}
+
+// UserLoader 根据令牌中的用户 ID 加载用户，用户不存在时返回 nil, nil
+type UserLoader func(userID uint) (*models.UserModel, error)
+
+// AuditRecorder 记录管理员代入期间的写请求
+type AuditRecorder interface {
+	RecordImpersonatedRequest(principal *Principal, method string, path string, status int, ip string) error
+}
+
+// AuthMiddleware 统一解析令牌并加载当前用户，处理器通过 CurrentPrincipal 读取结果
+type AuthMiddleware struct {
+	Auth         *Auth
+	LoadUser     UserLoader
+	Verification policy.VerificationPolicy
+	Audit        AuditRecorder
+}
+
+// RequireAuth 必须登录，未携带或携带无效令牌时返回 401
+// scopes 为允许 API 密钥访问时要求的授权范围，不传表示该接口不接受 API 密钥，也不接受管理员代入
+func (m *AuthMiddleware) RequireAuth(scopes ...policy.Scope) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !m.authenticate(ctx, scopes) {
+			return
+		}
+		if _, ok := CurrentPrincipal(ctx); !ok {
+			abortUnauthorized(ctx)
+			return
+		}
+		m.next(ctx)
+	}
+}
+
+// OptionalAuth 可选登录，未携带令牌时按匿名用户继续处理，携带了无效令牌仍返回 401
+// scopes 的含义与 RequireAuth 相同
+func (m *AuthMiddleware) OptionalAuth(scopes ...policy.Scope) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !m.authenticate(ctx, scopes) {
+			return
+		}
+		m.next(ctx)
+	}
+}
+
+// authenticate 解析令牌并写入认证主体，失败时中止请求并返回 false
+func (m *AuthMiddleware) authenticate(ctx *gin.Context, scopes []policy.Scope) bool {
+	if ctx.GetHeader("Authorization") == "" {
+		return true
+	}
+	claims, err := m.Auth.ParseClaims(ctx)
+	if err != nil {
+		abortUnauthorized(ctx)
+		return false
+	}
+	user, err := m.LoadUser(claims.UserID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return false
+	}
+	if user == nil {
+		abortUnauthorized(ctx)
+		return false
+	}
+	if claims.ViaAPIKey() {
+		if err := policy.AuthorizeScopes(claims.Scopes, scopes); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+			return false
+		}
+	}
+	principal := &Principal{User: user, Claims: claims}
+	if claims.Impersonated() {
+		if err := policy.AuthorizeImpersonation(scopes); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+			return false
+		}
+		actor, err := m.LoadUser(claims.ActorID)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+			return false
+		}
+		// 发起代入的管理员被删除或失去代入权限后，代入令牌随之失效
+		if actor == nil || !policy.Can(actor, policy.ImpersonateUsers) {
+			abortUnauthorized(ctx)
+			return false
+		}
+		principal.Actor = actor
+	}
+	ctx.Set(principalKey, principal)
+	return true
+}
+
+// next 继续处理请求，代入期间的写请求在处理完成后连同响应状态记入审计日志
+func (m *AuthMiddleware) next(ctx *gin.Context) {
+	ctx.Next()
+	principal, ok := CurrentPrincipal(ctx)
+	if !ok || principal.Actor == nil || m.Audit == nil {
+		return
+	}
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return
+	}
+	err := m.Audit.RecordImpersonatedRequest(principal, ctx.Request.Method, ctx.Request.URL.Path, ctx.Writer.Status(), ctx.ClientIP())
+	if err != nil {
+		log.Printf("记录审计日志失败：%v", err)
+	}
+}
+
+func abortUnauthorized(ctx *gin.Context) {
+	ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"未授权访问"}}})
+}
+
+// CurrentPrincipal 读取当前请求的认证主体，匿名请求返回 false
+func CurrentPrincipal(ctx *gin.Context) (*Principal, bool) {
+	value, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+// CurrentUser 当前登录用户，匿名请求返回 nil
+func CurrentUser(ctx *gin.Context) *models.UserModel {
+	if principal, ok := CurrentPrincipal(ctx); ok {
+		return principal.User
+	}
+	return nil
+}
+
+// CurrentUserID 当前登录用户的 ID，匿名请求返回 0
+func CurrentUserID(ctx *gin.Context) uint {
+	if principal, ok := CurrentPrincipal(ctx); ok {
+		return principal.User.ID
+	}
+	return 0
+}
+
+// RequireVerifiedEmail 按配置限制未验证邮箱的用户执行指定操作，需放在 RequireAuth 之后
+func (m *AuthMiddleware) RequireVerifiedEmail(action policy.Action) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !m.Verification.Allows(CurrentUser(ctx), action) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{policy.ErrEmailNotVerified.Error()}}})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequirePermission 要求当前用户拥有指定权限，需放在 RequireAuth 之后
+func RequirePermission(permission policy.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !policy.Can(CurrentUser(ctx), permission) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{policy.ErrForbidden.Error()}}})
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -2,16 +2,30 @@ package utils
 
 import (
 	"github.com/gin-gonic/gin"
+	"goDemo/config"
 	"net/http"
+	"slices"
+	"strings"
 )
 
-func CORSMiddleware() gin.HandlerFunc {
+func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
+	allowAll := slices.Contains(cfg.AllowOrigins, "*")
+	allowMethods := strings.Join(cfg.AllowMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowHeaders, ", ")
 	return func(c *gin.Context) {
-		// 允许所有来源跨域请求，实际生产环境可按需修改
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		// 按配置放行来源，配置为 * 时允许所有来源
+		origin := c.GetHeader("Origin")
+		if allowAll {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		} else if origin != "" && slices.Contains(cfg.AllowOrigins, origin) {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Methods", allowMethods)
+		c.Writer.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+		if cfg.AllowCredentials {
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"goDemo/config"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+)
+
+// SigningKey 一把 JWT 签名密钥，通过 kid 请求头区分
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	ActiveFrom time.Time // 从该时间起用于签名，早于该时间只发布不签名
+	signKey    interface{}
+	verifyKey  interface{}
+}
+
+// NewHMACKey 创建 HS256 密钥，只能用于本服务，不会出现在 JWKS 中
+func NewHMACKey(id string, secret []byte, activeFrom time.Time) *SigningKey {
+	return &SigningKey{
+		ID:         id,
+		Method:     jwt.SigningMethodHS256,
+		ActiveFrom: activeFrom,
+		signKey:    secret,
+		verifyKey:  secret,
+	}
+}
+
+// NewPrivateKey 根据 PEM 格式的私钥创建 RS256、ES256 或 EdDSA 密钥
+func NewPrivateKey(id string, algorithm string, pemData []byte, activeFrom time.Time) (*SigningKey, error) {
+	key := &SigningKey{ID: id, ActiveFrom: activeFrom}
+	switch algorithm {
+	case "RS256":
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return nil, err
+		}
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodRS256, private, &private.PublicKey
+	case "ES256":
+		private, err := jwt.ParseECPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return nil, err
+		}
+		if private.Curve != elliptic.P256() {
+			return nil, errors.New("ES256 需要 P-256 曲线的私钥")
+		}
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodES256, private, &private.PublicKey
+	case "EdDSA":
+		private, err := jwt.ParseEdPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return nil, err
+		}
+		edKey, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("EdDSA 仅支持 Ed25519 私钥")
+		}
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodEdDSA, edKey, edKey.Public()
+	default:
+		return nil, fmt.Errorf("不支持的签名算法：%s", algorithm)
+	}
+	return key, nil
+}
+
+// Keyring 支持多把密钥并存的密钥环
+// 签名时使用已生效的最新密钥；旧密钥被替换后在 Grace 时间内仍可用于验证，
+// 保证轮换前签发、尚未过期的令牌不会立即失效
+type Keyring struct {
+	keys  []*SigningKey // 按 ActiveFrom 升序
+	Grace time.Duration
+}
+
+func NewKeyring(grace time.Duration, keys ...*SigningKey) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("至少需要一把签名密钥")
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if seen[k.ID] {
+			return nil, fmt.Errorf("签名密钥 ID 重复：%s", k.ID)
+		}
+		seen[k.ID] = true
+	}
+	sorted := append([]*SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom)
+	})
+	return &Keyring{keys: sorted, Grace: grace}, nil
+}
+
+// SigningKey 返回当前用于签名的密钥
+func (r *Keyring) SigningKey(now time.Time) (*SigningKey, error) {
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if !r.keys[i].ActiveFrom.After(now) {
+			return r.keys[i], nil
+		}
+	}
+	return nil, errors.New("没有已生效的签名密钥")
+}
+
+// VerificationKey 根据 kid 查找可用于验证的密钥，已过宽限期的旧密钥视为不可用
+func (r *Keyring) VerificationKey(id string, now time.Time) (*SigningKey, error) {
+	for i, k := range r.keys {
+		if k.ID != id {
+			continue
+		}
+		if k.ActiveFrom.After(now) {
+			return nil, fmt.Errorf("签名密钥 %s 尚未生效", id)
+		}
+		if retiredAt, ok := r.retiredAt(i); ok && now.After(retiredAt.Add(r.Grace)) {
+			return nil, fmt.Errorf("签名密钥 %s 已停用", id)
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("未知的签名密钥：%s", id)
+}
+
+// retiredAt 密钥被下一把已生效的密钥取代的时间
+func (r *Keyring) retiredAt(i int) (time.Time, bool) {
+	if i+1 < len(r.keys) {
+		return r.keys[i+1].ActiveFrom, true
+	}
+	return time.Time{}, false
+}
+
+// JWK 单个公钥的 JSON Web Key 表示
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet /.well-known/jwks.json 的响应体
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 返回仍可用于验证以及即将生效的非对称公钥，
+// 提前发布新密钥，其他服务在轮换发生前就能缓存到
+func (r *Keyring) JWKS(now time.Time) JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for i, k := range r.keys {
+		if retiredAt, ok := r.retiredAt(i); ok && now.After(retiredAt.Add(r.Grace)) {
+			continue
+		}
+		if jwk, ok := k.jwk(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+func (k *SigningKey) jwk() (JWK, bool) {
+	encode := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encode(pub.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty, jwk.Crv = "EC", pub.Curve.Params().Name
+		jwk.X = encode(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = encode(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+		jwk.X = encode(pub)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
+
+// BuildKeyring 根据配置构建密钥环
+// auth.secret 作为 kid 为 default 的 HS256 密钥，与 auth.signing_keys 可以同时存在，便于平滑迁移
+func BuildKeyring(cfg config.AuthConfig) (*Keyring, error) {
+	var keys []*SigningKey
+	if cfg.Secret != "" {
+		keys = append(keys, NewHMACKey("default", []byte(cfg.Secret.Value()), time.Time{}))
+	}
+	startedAt := time.Now()
+	for _, kc := range cfg.SigningKeys {
+		// 未配置生效时间时按启动时间计算，旧密钥从此刻起进入宽限期；
+		// 多实例部署建议显式配置 active_from，保证各实例同时切换
+		activeFrom := kc.ActiveFrom
+		if activeFrom.IsZero() {
+			activeFrom = startedAt
+		}
+		if kc.Algorithm == "HS256" {
+			keys = append(keys, NewHMACKey(kc.ID, []byte(kc.Secret.Value()), activeFrom))
+			continue
+		}
+		pemData, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取签名密钥 %s 失败：%w", kc.ID, err)
+		}
+		key, err := NewPrivateKey(kc.ID, kc.Algorithm, pemData, activeFrom)
+		if err != nil {
+			return nil, fmt.Errorf("解析签名密钥 %s 失败：%w", kc.ID, err)
+		}
+		keys = append(keys, key)
+	}
+	return NewKeyring(cfg.KeyGracePeriod.Std(), keys...)
+}
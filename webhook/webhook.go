@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goDemo/config"
+	"net/http"
+	"time"
+)
+
+// 请求头，接收方用 Signature 校验请求确实来自本服务，用 Delivery 对重试去重
+const (
+	HeaderEvent     = "X-Realworld-Event"
+	HeaderDelivery  = "X-Realworld-Delivery"
+	HeaderSignature = "X-Realworld-Signature"
+)
+
+// Event 推送的事件，同一事件重试时 ID 不变
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// Sender 向配置的地址推送事件，非 2xx 响应或网络错误时按指数退避重试
+type Sender struct {
+	URLs        []string
+	Secret      string
+	MaxAttempts int
+	Client      *http.Client
+	// Backoff 第一次重试前的等待时间，之后每次翻倍
+	Backoff time.Duration
+}
+
+// New 根据配置创建推送器，没有配置地址时返回 nil
+func New(cfg config.WebhookConfig) *Sender {
+	if len(cfg.URLs) == 0 {
+		return nil
+	}
+	return &Sender{
+		URLs:        cfg.URLs,
+		Secret:      string(cfg.Secret),
+		MaxAttempts: cfg.MaxAttempts,
+		Client:      &http.Client{Timeout: cfg.Timeout.Std()},
+		Backoff:     time.Second,
+	}
+}
+
+// NewEvent 创建带随机 ID 的事件
+func NewEvent(eventType string, data interface{}) (Event, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return Event{}, err
+	}
+	return Event{ID: hex.EncodeToString(b), Type: eventType, CreatedAt: time.Now().UTC(), Data: data}, nil
+}
+
+// Send 依次推送到所有地址，某个地址失败不影响其他地址，返回所有失败地址的错误
+func (s *Sender) Send(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, url := range s.URLs {
+		if err := s.deliver(url, event, body); err != nil {
+			errs = append(errs, fmt.Errorf("推送 %s 到 %s 失败：%w", event.Type, url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Sender) deliver(url string, event Event, body []byte) error {
+	var err error
+	wait := s.Backoff
+	for attempt := 1; attempt <= s.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+		if err = s.post(url, event, body); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (s *Sender) post(url string, event Event, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID)
+	if s.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(s.Secret, body))
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("响应状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign 计算请求体的 HMAC-SHA256 签名（十六进制）
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}